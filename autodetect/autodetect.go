@@ -0,0 +1,112 @@
+package autodetect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"portscanner/compose"
+	"portscanner/scanner"
+)
+
+// Finding is one port a project file says the project listens on
+type Finding struct {
+	Spec scanner.PortSpec
+	File string // relative to the project root
+	Line int
+}
+
+// Location renders "package.json:12"
+func (f Finding) Location() string {
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// source reads the ports declared by one kind of project file
+type source struct {
+	patterns []string // globs relative to the project root
+	parse    func(data []byte) []Finding
+}
+
+// sourcesIn lists the sources for the project in root. Compose files are
+// interpolated with root's .env like docker compose does.
+func sourcesIn(root string) []source {
+	parseRootCompose := func(data []byte) []Finding {
+		return parseCompose(data, compose.Environment(root))
+	}
+
+	return []source{
+		{[]string{"package.json"}, parsePackageJSON},
+		{[]string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}, parseRootCompose},
+		{[]string{".env", ".env.local"}, parseDotEnv},
+		{[]string{"vite.config.*", "next.config.*"}, parseJSConfig},
+		{[]string{"Procfile", "Makefile"}, parseCommands},
+		{[]string{"*.py"}, parsePython},
+		{[]string{"application.properties", "src/main/resources/application.properties"}, parseSpringProperties},
+		{[]string{"application.yml", "application.yaml", "src/main/resources/application.yml", "src/main/resources/application.yaml"}, parseSpringYAML},
+	}
+}
+
+// Detect collects the ports declared by the files in root, ordered by
+// file and line. Unreadable files are skipped.
+func Detect(root string) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+
+	for _, src := range sourcesIn(root) {
+		for _, pattern := range src.patterns {
+			paths, _ := filepath.Glob(filepath.Join(root, pattern))
+			for _, path := range paths {
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+
+				data, err := os.ReadFile(path)
+				if err != nil {
+					continue
+				}
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					rel = path
+				}
+				for _, finding := range src.parse(data) {
+					finding.File = rel
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// Specs returns each detected port once, in order of first appearance
+func Specs(findings []Finding) []scanner.PortSpec {
+	var specs []scanner.PortSpec
+	seen := make(map[scanner.PortSpec]bool)
+	for _, finding := range findings {
+		if !seen[finding.Spec] {
+			seen[finding.Spec] = true
+			specs = append(specs, finding.Spec)
+		}
+	}
+	return specs
+}
+
+// Label records on each status where its port was detected
+func Label(statuses []*scanner.PortStatus, findings []Finding) {
+	for _, status := range statuses {
+		for _, finding := range findings {
+			if finding.Spec == status.Spec() {
+				status.DetectedIn = append(status.DetectedIn, finding.Location())
+			}
+		}
+	}
+}
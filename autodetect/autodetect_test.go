@@ -0,0 +1,94 @@
+package autodetect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func locations(findings []Finding) []string {
+	var found []string
+	for _, finding := range findings {
+		found = append(found, finding.Spec.String()+"@"+finding.Location())
+	}
+	return found
+}
+
+func TestDetectComposeUsesDotEnv(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"compose.yaml": `services:
+  web:
+    ports:
+      - "${PORTSCANNER_TEST_WEB_PORT}:80"
+      - "${PORTSCANNER_TEST_ADMIN_PORT:-9000}:9000"
+`,
+		// No PORT= line, so parseDotEnv finds nothing of its own
+		".env": "PORTSCANNER_TEST_WEB_PORT=8080\n",
+	})
+
+	want := []string{"8080/tcp@compose.yaml:4", "9000/tcp@compose.yaml:5"}
+	if got := locations(Detect(root)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectComposeSkipsOnlyBadEntries(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"docker-compose.yml": `services:
+  db:
+    ports:
+      - "${PORTSCANNER_TEST_DB_PORT:?set PORTSCANNER_TEST_DB_PORT}:5432"
+      - "6432:6432"
+  web:
+    ports:
+      - target: 80
+        published: "3000"
+`,
+	})
+
+	want := []string{"6432/tcp@docker-compose.yml:5", "3000/tcp@docker-compose.yml:8"}
+	if got := locations(Detect(root)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectProjectFiles(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"package.json":   `{"scripts": {"dev": "vite"}}`,
+		"vite.config.ts": "export default defineConfig({\n  server: { port: 5173, hmr: { port: 24678 } },\n})\n",
+		".env.local":     "PORT=3000\n",
+		"src/main/resources/application.properties": "server.port=8080\n",
+		"compose.yaml": `services:
+  web:
+    ports:
+      - "8000-8010:80"
+      - "5432:5432"
+`,
+	})
+
+	want := []string{
+		"3000/tcp@.env.local:1",
+		"5432/tcp@compose.yaml:5", // docker picks whichever port of 8000-8010 is free
+		"8080/tcp@src/main/resources/application.properties:1",
+		"5173/tcp@vite.config.ts:2",
+	}
+	if got := locations(Detect(root)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,279 @@
+package autodetect
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"portscanner/compose"
+	"portscanner/scanner"
+)
+
+// Port flags and variables in shell commands: "vite --port 5173",
+// "next dev -p 3001", "PORT=4000 node server.js",
+// "python manage.py runserver 0.0.0.0:8000", "gunicorn -b :8000 app:app"
+var commandPorts = []*regexp.Regexp{
+	regexp.MustCompile(`--port[= ](\d+)\b`),
+	regexp.MustCompile(`(?:^|\s)-p\s+(\d+)(?:[\s"';&]|$)`),
+	regexp.MustCompile(`\bPORT=["']?(\d+)\b`),
+	regexp.MustCompile(`\brunserver\s+(?:\S*:)?(\d+)\b`),
+	regexp.MustCompile(`(?:--bind|-b)[= ]\S*:(\d+)\b`),
+}
+
+var (
+	dotEnvPort     = regexp.MustCompile(`^\s*(?:export\s+)?PORT\s*=\s*["']?(\d+)`)
+	pythonRunPort  = regexp.MustCompile(`\.run\([^)]*\bport\s*=\s*(\d+)`) // app.run(port=5000)
+	springPortProp = regexp.MustCompile(`^\s*server\.port\s*[=:]\s*(\d+)`)
+)
+
+// tcpFinding builds a TCP finding from a regexp capture, ignoring anything
+// that isn't a valid port
+func tcpFinding(portStr string, line int) (Finding, bool) {
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return Finding{}, false
+	}
+	return Finding{Spec: scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP}, Line: line}, true
+}
+
+// matchLines applies patterns to every line, reporting the first capture
+// group of each match
+func matchLines(data []byte, patterns ...*regexp.Regexp) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(string(data), "\n") {
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+				if finding, ok := tcpFinding(match[1], i+1); ok {
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// parsePackageJSON reads the port flags of every npm script
+func parsePackageJSON(data []byte) []Finding {
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	// JSON decoding loses line numbers, so find each script's line by its key
+	lines := strings.Split(string(data), "\n")
+	scriptsLine := 0
+	for i, line := range lines {
+		if strings.Contains(line, `"scripts"`) {
+			scriptsLine = i
+			break
+		}
+	}
+
+	var findings []Finding
+	for name, command := range pkg.Scripts {
+		key, _ := json.Marshal(name)
+		lineNo := scriptsLine + 1
+		for i := scriptsLine; i < len(lines); i++ {
+			if strings.Contains(lines[i], string(key)) {
+				lineNo = i + 1
+				break
+			}
+		}
+		for _, finding := range matchLines([]byte(command), commandPorts...) {
+			finding.Line = lineNo
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func parseCommands(data []byte) []Finding {
+	return matchLines(data, commandPorts...)
+}
+
+func parseDotEnv(data []byte) []Finding {
+	return matchLines(data, dotEnvPort)
+}
+
+// parseJSConfig reads server.port, or a port of the config object itself,
+// from a vite or next config: "server: { port: 5173 }". Other nested ports
+// such as server.hmr.port and preview.port belong to other servers.
+func parseJSConfig(data []byte) []Finding {
+	var findings []Finding
+	var path []string // property of each open bracket, "" when it has none
+	property := ""    // property whose value comes next
+	line := 1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			// Stop before the newline so it is counted
+			skip := bytes.IndexByte(data[i:], '\n')
+			if skip == -1 {
+				return findings
+			}
+			i += skip - 1
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			skip := bytes.Index(data[i+2:], []byte("*/"))
+			if skip == -1 {
+				return findings
+			}
+			line += bytes.Count(data[i:i+2+skip], []byte("\n"))
+			i += 2 + skip + 1
+		case c == '"' || c == '\'' || c == '`':
+			skip := bytes.IndexByte(data[i+1:], c)
+			if skip == -1 {
+				return findings
+			}
+			line += bytes.Count(data[i:i+1+skip], []byte("\n"))
+			i += 1 + skip
+			property = ""
+		case c == '{' || c == '[' || c == '(':
+			path = append(path, property)
+			property = ""
+		case c == '}' || c == ']' || c == ')':
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			property = ""
+		case c == '_' || c == '$' || isLetter(c):
+			j := i
+			for j < len(data) && (data[j] == '_' || data[j] == '$' || isLetter(data[j]) || isDigit(data[j])) {
+				j++
+			}
+			name := string(data[i:j])
+			k := j
+			for k < len(data) && (data[k] == ' ' || data[k] == '\t') {
+				k++
+			}
+			i = j - 1
+			property = ""
+			if k == len(data) || data[k] != ':' {
+				continue
+			}
+			i = k
+			property = name
+			if name != "port" || !configPortPath(path) {
+				continue
+			}
+			for k++; k < len(data) && (data[k] == ' ' || data[k] == '\t'); k++ {
+			}
+			digits := k
+			for digits < len(data) && isDigit(data[digits]) {
+				digits++
+			}
+			if finding, ok := tcpFinding(string(data[k:digits]), line); ok {
+				findings = append(findings, finding)
+			}
+		default:
+			property = ""
+		}
+	}
+	return findings
+}
+
+// configPortPath reports whether a port property under the brackets of
+// path is the dev server's: the config's own or server.port
+func configPortPath(path []string) bool {
+	var named []string
+	for _, property := range path {
+		if property != "" {
+			named = append(named, property)
+		}
+	}
+	return len(named) == 0 || (len(named) == 1 && named[0] == "server")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parsePython(data []byte) []Finding {
+	return matchLines(data, pythonRunPort)
+}
+
+func parseSpringProperties(data []byte) []Finding {
+	return matchLines(data, springPortProp)
+}
+
+// parseSpringYAML reads server.port from application.yml
+func parseSpringYAML(data []byte) []Finding {
+	var findings []Finding
+	// Spring allows several documents in one file, one per profile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			return findings
+		}
+		port := lookup(&doc, "server", "port")
+		if port == nil {
+			port = lookup(&doc, "server.port")
+		}
+		if port != nil {
+			if finding, ok := tcpFinding(port.Value, port.Line); ok {
+				findings = append(findings, finding)
+			}
+		}
+	}
+}
+
+// parseCompose reads the host side of every services.*.ports entry, with
+// variables from env. An entry that can't be read doesn't hide the others.
+func parseCompose(data []byte, env map[string]string) []Finding {
+	project, err := compose.ParseLenient(data, env)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, service := range project.Services {
+		for _, port := range service.Ports {
+			if port.RangeEnd != 0 {
+				// Docker picks whichever port of the range is free
+				continue
+			}
+			findings = append(findings, Finding{Spec: port.Spec, Line: port.Line})
+		}
+	}
+	return findings
+}
+
+// lookup follows a path of mapping keys from node, returning nil when one
+// is missing
+func lookup(node *yaml.Node, path ...string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,75 @@
+package autodetect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// lines renders findings as "PORT@LINE", by line: package.json scripts
+// come from a map and have no order of their own
+func lines(findings []Finding) []string {
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	var found []string
+	for _, finding := range findings {
+		found = append(found, fmt.Sprintf("%d@%d", finding.Spec.Port, finding.Line))
+	}
+	return found
+}
+
+func TestSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) []Finding
+		data  string
+		want  []string
+	}{
+		{"package.json scripts", parsePackageJSON, `{
+  "name": "web",
+  "scripts": {
+    "dev": "vite --port 5173",
+    "start": "next start -p 3001",
+    "api": "PORT=4000 node server.js",
+    "lint": "eslint . --max-warnings 0"
+  }
+}`, []string{"5173@4", "3001@5", "4000@6"}},
+		{"package.json --port=", parsePackageJSON, `{"scripts": {"preview": "vite preview --port=4173"}}`, []string{"4173@1"}},
+		{"package.json without scripts", parsePackageJSON, `{"name": "lib"}`, nil},
+		{"invalid package.json", parsePackageJSON, `{"scripts": `, nil},
+		{".env", parseDotEnv, "# web\nPORT=8080\nexport PORT=\"8081\"\nDB_PORT=5432\nPORT=99999\n", []string{"8080@2", "8081@3"}},
+		{"vite server.port", parseJSConfig, `import { defineConfig } from 'vite'
+
+export default defineConfig({
+  plugins: [react({ port: 1 })],
+  server: {
+    port: 5173,
+    hmr: { port: 24678 },
+  },
+  preview: { port: 4173 },
+})
+`, []string{"5173@6"}},
+		{"top-level port", parseJSConfig, "module.exports = {\n  port: 3000,\n}\n", []string{"3000@2"}},
+		{"config function", parseJSConfig, "export default defineConfig(({ mode }) => {\n  return { server: { port: 5174 } }\n})\n", []string{"5174@2"}},
+		{"ports in comments and strings", parseJSConfig, "// port: 1111\n/* server: {\n port: 2222 } */\nconst s = 'port: 3333'\nexport default { server: { port: 5175 } }\n", []string{"5175@5"}},
+		{"port from the environment", parseJSConfig, "export default { server: { port: Number(process.env.PORT) } }\n", nil},
+		{"django runserver", parseCommands, "web: python manage.py runserver 0.0.0.0:8000\n", []string{"8000@1"}},
+		{"gunicorn bind", parseCommands, "web: gunicorn -b :8001 app:app\nworker: celery -A app worker\n", []string{"8001@1"}},
+		{"makefile", parseCommands, "dev:\n\tuvicorn main:app --port 8002\n", []string{"8002@2"}},
+		{"flask app.run", parsePython, "if __name__ == \"__main__\":\n    app.run(host=\"0.0.0.0\", port=5000, debug=True)\n", []string{"5000@2"}},
+		{"python without app.run", parsePython, "PORT = 5001\n", nil},
+		{"application.properties", parseSpringProperties, "spring.application.name=api\nserver.port=8080\nmanagement.server.port=8081\n", []string{"8080@2"}},
+		{"application.properties colon", parseSpringProperties, "server.port: 8082\n", []string{"8082@1"}},
+		{"application.yml", parseSpringYAML, "spring:\n  application:\n    name: api\nserver:\n  port: 8083\n", []string{"8083@5"}},
+		{"application.yml dotted key", parseSpringYAML, "server.port: 8084\n", []string{"8084@1"}},
+		{"application.yml profiles", parseSpringYAML, "server:\n  port: 8085\n---\nspring.config.activate.on-profile: dev\nserver:\n  port: 8086\n", []string{"8085@2", "8086@6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lines(tt.parse([]byte(tt.data))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"portscanner/compose"
+	"portscanner/formatter"
+	"portscanner/scanner"
+)
+
+// checkCompose checks every host port a compose project publishes before
+// docker compose up does, the compose subcommand. Without files, the
+// compose file and its override are looked up like docker compose does.
+func checkCompose(files []string, format, backend, projectName string) int {
+	startedAt := time.Now()
+
+	if len(files) == 0 {
+		found, err := compose.Find(".")
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitUsage
+		}
+		files = found
+	}
+	project, err := compose.Load(files)
+	if err != nil {
+		fmt.Printf("❌ Invalid compose file: %v\n", err)
+		return exitUsage
+	}
+	if flagSet("project") {
+		project.Name = projectName
+	}
+
+	bindings := project.Bindings()
+	fmt.Fprintf(os.Stderr, "🐳 Checking %d published port(s) from %s\n", len(bindings), strings.Join(files, ", "))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// A port published on one host IP is checked like --bind checks it
+	var hostIPs []string
+	specs := make(map[string][]scanner.PortSpec)
+	queued := make(map[compose.Binding]bool)
+	for _, binding := range bindings {
+		if _, ok := specs[binding.HostIP]; !ok {
+			hostIPs = append(hostIPs, binding.HostIP)
+		}
+		for _, spec := range binding.Candidates() {
+			if candidate := (compose.Binding{HostIP: binding.HostIP, Spec: spec}); !queued[candidate] {
+				queued[candidate] = true
+				specs[binding.HostIP] = append(specs[binding.HostIP], spec)
+			}
+		}
+	}
+
+	ps := scanner.NewScanner(scanner.Options{Backend: backend})
+	results := make(map[compose.Binding]*scanner.PortStatus)
+	for _, hostIP := range hostIPs {
+		hostScanner := ps
+		if hostIP != "" {
+			hostScanner = scanner.NewScanner(scanner.Options{BindAddress: hostIP, Backend: backend})
+		}
+		checked, err := hostScanner.CheckPorts(ctx, specs[hostIP])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Could not check published ports: %v\n", err)
+			return exitScanError
+		}
+		for _, status := range checked {
+			results[compose.Binding{HostIP: hostIP, Spec: status.Spec()}] = status
+		}
+	}
+
+	// A range is reported by the port docker would pick, or by its first
+	// port when every one is taken
+	statuses := make(map[compose.Binding]*scanner.PortStatus)
+	var checked []*scanner.PortStatus
+	for _, binding := range bindings {
+		candidates := binding.Candidates()
+		status := results[compose.Binding{HostIP: binding.HostIP, Spec: candidates[0]}]
+		for _, spec := range candidates {
+			if candidate := results[compose.Binding{HostIP: binding.HostIP, Spec: spec}]; candidate.IsAvailable {
+				status = candidate
+				break
+			}
+		}
+		statuses[binding] = status
+		if !slices.Contains(checked, status) {
+			checked = append(checked, status)
+		}
+	}
+
+	analyzeOwners(checked)
+	meta := scanMetadata(project.Name, "", ps.Backend(), startedAt)
+
+	switch format {
+	case "json":
+		formatter := formatter.NewJSONFormatter()
+		output, err := formatter.ComposeReport(project, statuses, meta)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to encode JSON: %v\n", err)
+			break
+		}
+		fmt.Println(output)
+	case "simple":
+		printComposeSimple(project, statuses)
+	default:
+		formatter := formatter.NewComposeFormatter()
+		fmt.Println(formatter.ServiceTable(project, statuses))
+
+		// The detailed view also analyzes whatever holds the ports
+		var occupied []*scanner.PortStatus
+		for _, status := range checked {
+			if !status.IsAvailable {
+				occupied = append(occupied, status)
+			}
+		}
+		if format == "detailed" && len(occupied) > 0 {
+			// A port free on every address is free on any host IP published on
+			printDetailedOutput(occupied, project.Name, "")
+		}
+	}
+
+	return composeExitCode(project, statuses)
+}
+
+func printComposeSimple(project *compose.Project, statuses map[compose.Binding]*scanner.PortStatus) {
+	fmt.Printf("🔍 Checking %d published port(s)...\n\n", len(statuses))
+
+	for _, service := range project.Services {
+		for _, port := range service.Ports {
+			status := statuses[port.Binding()]
+			published := port.Spec.String()
+			if port.RangeEnd != 0 {
+				published = fmt.Sprintf("%d-%d/%s", port.Spec.Port, port.RangeEnd, port.Spec.Protocol)
+			}
+			name := fmt.Sprintf("%s %s", service.Name, published)
+			if port.HostIP != "" {
+				name = fmt.Sprintf("%s %s on %s", service.Name, published, port.HostIP)
+			}
+
+			if clashes := project.ClashesWith(port); len(clashes) > 0 {
+				var others []string
+				for _, clash := range clashes {
+					others = append(others, fmt.Sprintf("%s (%s)", clash.Service, clash.Port.Location()))
+				}
+				fmt.Printf("🚨 %s: Also published by %s\n", name, strings.Join(others, ", "))
+			}
+
+			if project.Runs(service.Name, status.Container) {
+				fmt.Printf("🐳 %s: Already running in %s\n", name, status.Container.Name)
+			} else if status.Error != "" && status.Container == nil {
+				fmt.Printf("🚨 %s: Error - %s\n", name, status.Error)
+			} else if status.Reason != "" && status.Reason != scanner.ReasonInUse {
+				reason, remedy := formatter.ExplainReason(status)
+				fmt.Printf("🚨 %s: %s - %s\n", name, reason, remedy)
+			} else if status.IsAvailable {
+				fmt.Printf("✅ %s: Available\n", name)
+			} else {
+				fmt.Printf("🚨 %s: Occupied by %s\n", name, describeOccupant(status))
+			}
+		}
+	}
+}
+
+// composeExitCode fails when a published port is taken on the host or
+// published twice. Privileged ports don't count: the docker daemon runs as
+// root. As in exitCode, exitScanError wins over exitOccupied.
+func composeExitCode(project *compose.Project, statuses map[compose.Binding]*scanner.PortStatus) int {
+	code := exitOK
+
+	for _, service := range project.Services {
+		for _, port := range service.Ports {
+			status := statuses[port.Binding()]
+			if checkFailed(status) {
+				code = exitScanError
+				continue
+			}
+
+			taken := !status.IsAvailable && status.Reason != scanner.ReasonPermissionDenied &&
+				!project.Runs(service.Name, status.Container)
+			if (taken || len(project.ClashesWith(port)) > 0) && code == exitOK {
+				code = exitOccupied
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,343 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"portscanner/scanner"
+)
+
+// FileNames are the compose files docker compose looks for, in its order
+var FileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// Project is the merged result of a compose file and its overrides
+type Project struct {
+	Name     string // top-level name:, or the directory name
+	Dir      string // directory of the first file, where .env is read from
+	Files    []string
+	Services []Service // in order of first appearance
+}
+
+type Service struct {
+	Name  string
+	Ports []Port
+
+	replacePorts bool // ports: !reset or !override in an override file
+}
+
+// Port is the host side of one ports: entry. A range such as
+// "9090-9091:8080-8081" yields one Port per host port, while a host range
+// for a single container port ("8000-8010:80") yields one Port with
+// RangeEnd set: docker picks any free host port from Spec.Port to RangeEnd.
+type Port struct {
+	HostIP   string // "" when published on every interface
+	Spec     scanner.PortSpec
+	RangeEnd int    // 0 unless any port of a host range will do
+	Target   string // container port, as written
+	File     string // relative to the project directory
+	Line     int
+}
+
+// Location renders "docker-compose.yml:12"
+func (p Port) Location() string {
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// Binding is what a published port takes on the host
+type Binding struct {
+	HostIP   string // "" for every interface, including 0.0.0.0 and ::
+	Spec     scanner.PortSpec
+	RangeEnd int // see Port.RangeEnd
+}
+
+func (p Port) Binding() Binding {
+	hostIP := p.HostIP
+	if ip := net.ParseIP(hostIP); ip != nil && ip.IsUnspecified() {
+		hostIP = ""
+	}
+	return Binding{HostIP: hostIP, Spec: p.Spec, RangeEnd: p.RangeEnd}
+}
+
+// Candidates returns the host ports the binding may take: the one port, or
+// every port of a range docker picks from
+func (b Binding) Candidates() []scanner.PortSpec {
+	if b.RangeEnd == 0 {
+		return []scanner.PortSpec{b.Spec}
+	}
+	specs := make([]scanner.PortSpec, 0, b.RangeEnd-b.Spec.Port+1)
+	for port := b.Spec.Port; port <= b.RangeEnd; port++ {
+		specs = append(specs, scanner.PortSpec{Port: port, Protocol: b.Spec.Protocol})
+	}
+	return specs
+}
+
+// overlaps reports whether two bindings can't both be published. Docker
+// picks a port of a range that is still free, so ranges never clash.
+func (b Binding) overlaps(other Binding) bool {
+	if b.RangeEnd != 0 || other.RangeEnd != 0 {
+		return false
+	}
+	return b.Spec == other.Spec && (b.HostIP == "" || other.HostIP == "" || b.HostIP == other.HostIP)
+}
+
+// Find walks up from dir like docker compose does and returns the first
+// compose file found, followed by its override file when there is one
+func Find(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			files := []string{path}
+			stem := strings.TrimSuffix(name, filepath.Ext(name))
+			for _, ext := range []string{".yaml", ".yml"} {
+				override := filepath.Join(dir, stem+".override"+ext)
+				if _, err := os.Stat(override); err == nil {
+					files = append(files, override)
+					break
+				}
+			}
+			return files, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir { // Reached root
+			return nil, errors.New("no compose file found in this directory or its parents")
+		}
+		dir = parent
+	}
+}
+
+// Load reads files in order, each one overriding the ones before, with
+// variables interpolated from the environment and the project's .env
+func Load(files []string) (*Project, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no compose file given")
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(files[0]))
+	if err != nil {
+		return nil, err
+	}
+	project := &Project{Dir: dir, Files: files}
+	env := Environment(dir)
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, err := Parse(data, env)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		for i := range file.Services {
+			for j := range file.Services[i].Ports {
+				file.Services[i].Ports[j].File = rel
+			}
+		}
+		project.merge(file)
+	}
+
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		project.Name = name
+	}
+	if project.Name == "" {
+		project.Name = projectName(filepath.Base(dir))
+	}
+	return project, nil
+}
+
+// Parse reads the name and the published ports of a single compose file.
+// Unset variables interpolate to "" like in docker compose.
+func Parse(data []byte, env map[string]string) (*Project, error) {
+	return parse(data, env, false)
+}
+
+// ParseLenient is Parse for a best-effort read of the ports: a name or a
+// ports entry that fails to parse or interpolate is skipped instead of
+// failing the whole file
+func ParseLenient(data []byte, env map[string]string) (*Project, error) {
+	return parse(data, env, true)
+}
+
+func parse(data []byte, env map[string]string, lenient bool) (*Project, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	project := &Project{}
+	if name := lookup(&doc, "name"); name != nil {
+		value, err := Interpolate(name.Value, env)
+		if err != nil && !lenient {
+			return nil, fmt.Errorf("line %d: %w", name.Line, err)
+		}
+		project.Name = value
+	}
+
+	services := lookup(&doc, "services")
+	if services == nil {
+		return project, nil
+	}
+	if services.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: services must be a mapping", services.Line)
+	}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		service := Service{Name: services.Content[i].Value}
+		ports := lookup(services.Content[i+1], "ports")
+		if ports != nil && ports.Kind == yaml.SequenceNode {
+			for _, entry := range ports.Content {
+				published, err := parsePorts(entry, env)
+				if err != nil && lenient {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("service %s, line %d: %w", service.Name, entry.Line, err)
+				}
+				service.Ports = append(service.Ports, published...)
+			}
+		}
+		// !reset and !override replace the ports of earlier files
+		service.replacePorts = ports != nil && (ports.Tag == "!reset" || ports.Tag == "!override")
+		project.Services = append(project.Services, service)
+	}
+	return project, nil
+}
+
+// merge applies an override file: new services are added and the ports of
+// existing ones are appended, dropping exact duplicates
+func (p *Project) merge(file *Project) {
+	if file.Name != "" {
+		p.Name = file.Name
+	}
+
+	for _, override := range file.Services {
+		existing := p.service(override.Name)
+		if existing == nil {
+			p.Services = append(p.Services, override)
+			continue
+		}
+		if override.replacePorts {
+			existing.Ports = nil
+		}
+		for _, port := range override.Ports {
+			if !existing.publishes(port) {
+				existing.Ports = append(existing.Ports, port)
+			}
+		}
+	}
+}
+
+func (p *Project) service(name string) *Service {
+	for i := range p.Services {
+		if p.Services[i].Name == name {
+			return &p.Services[i]
+		}
+	}
+	return nil
+}
+
+func (s *Service) publishes(port Port) bool {
+	for _, existing := range s.Ports {
+		if existing.Binding() == port.Binding() && existing.Target == port.Target {
+			return true
+		}
+	}
+	return false
+}
+
+// Bindings returns every host binding once, in declaration order
+func (p *Project) Bindings() []Binding {
+	var bindings []Binding
+	seen := make(map[Binding]bool)
+	for _, service := range p.Services {
+		for _, port := range service.Ports {
+			if binding := port.Binding(); !seen[binding] {
+				seen[binding] = true
+				bindings = append(bindings, binding)
+			}
+		}
+	}
+	return bindings
+}
+
+// Clash is another ports: entry publishing the same host port
+type Clash struct {
+	Service string
+	Port    Port
+}
+
+// ClashesWith returns the other entries, in any service, that publish a
+// host port overlapping port. docker compose up fails on the second one.
+func (p *Project) ClashesWith(port Port) []Clash {
+	var clashes []Clash
+	for _, service := range p.Services {
+		for _, other := range service.Ports {
+			if other == port {
+				continue
+			}
+			if other.Binding().overlaps(port.Binding()) {
+				clashes = append(clashes, Clash{Service: service.Name, Port: other})
+			}
+		}
+	}
+	return clashes
+}
+
+// Runs reports whether container is this project's service, i.e. the
+// project is already up and holds the port itself
+func (p *Project) Runs(service string, container *scanner.Container) bool {
+	return container != nil && container.ComposeProject == p.Name && container.ComposeService == service
+}
+
+// Project names are lowercase letters, digits, dashes and underscores
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+func projectName(dir string) string {
+	return invalidNameChars.ReplaceAllString(strings.ToLower(dir), "")
+}
+
+// lookup follows a path of mapping keys from node, returning nil when one
+// is missing
+func lookup(node *yaml.Node, path ...string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
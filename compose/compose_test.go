@@ -0,0 +1,195 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under dir, creating parent directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// published renders a service's ports as "HOSTIP:PORT/PROTO->TARGET @FILE:LINE"
+func published(service *Service) []string {
+	var ports []string
+	for _, port := range service.Ports {
+		ports = append(ports, port.HostIP+":"+port.Spec.String()+"->"+port.Target+" @"+port.Location())
+	}
+	return ports
+}
+
+func TestLoadMergesOverrides(t *testing.T) {
+	base := `name: shop
+services:
+  web:
+    ports:
+      - "8080:80"
+  api:
+    ports:
+      - "9000:9000"
+  db:
+    ports:
+      - "5432:5432"
+`
+	override := `services:
+  web:
+    ports:
+      - "8080:80"
+      - "127.0.0.1:8443:443"
+  api:
+    ports: !override
+      - "9001:9000"
+  db:
+    ports: !reset []
+  cache:
+    ports:
+      - "6379:6379"
+`
+
+	tests := []struct {
+		name      string
+		files     []string
+		wantName  string
+		wantPorts map[string][]string
+	}{
+		{
+			name:     "base only",
+			files:    []string{"compose.yaml"},
+			wantName: "shop",
+			wantPorts: map[string][]string{
+				"web": {":8080/tcp->80 @compose.yaml:5"},
+				"api": {":9000/tcp->9000 @compose.yaml:8"},
+				"db":  {":5432/tcp->5432 @compose.yaml:11"},
+			},
+		},
+		{
+			name:     "override appends, replaces and resets",
+			files:    []string{"compose.yaml", "compose.override.yaml"},
+			wantName: "shop",
+			wantPorts: map[string][]string{
+				// The repeated 8080:80 is dropped, the new entry appended
+				"web":   {":8080/tcp->80 @compose.yaml:5", "127.0.0.1:8443/tcp->443 @compose.override.yaml:5"},
+				"api":   {":9001/tcp->9000 @compose.override.yaml:8"},
+				"db":    nil,
+				"cache": {":6379/tcp->6379 @compose.override.yaml:13"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"compose.yaml": base, "compose.override.yaml": override})
+			var files []string
+			for _, name := range tt.files {
+				files = append(files, filepath.Join(dir, name))
+			}
+
+			project, err := Load(files)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if project.Name != tt.wantName {
+				t.Errorf("Load() name = %q, want %q", project.Name, tt.wantName)
+			}
+			if len(project.Services) != len(tt.wantPorts) {
+				t.Errorf("Load() has %d services, want %d", len(project.Services), len(tt.wantPorts))
+			}
+			for name, want := range tt.wantPorts {
+				service := project.service(name)
+				if service == nil {
+					t.Errorf("Load() has no service %s", name)
+					continue
+				}
+				if got := published(service); !reflect.DeepEqual(got, want) {
+					t.Errorf("service %s ports = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClashesWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports map[string]string // service → its only ports: entry
+		want  int               // clashes of web's entry
+	}{
+		{"same port", map[string]string{"web": `"8080:80"`, "admin": `"8080:81"`}, 1},
+		{"every interface and one address", map[string]string{"web": `"8080:80"`, "admin": `"127.0.0.1:8080:81"`}, 1},
+		{"two addresses", map[string]string{"web": `"127.0.0.1:8080:80"`, "admin": `"127.0.0.2:8080:81"`}, 0},
+		{"unspecified address", map[string]string{"web": `"0.0.0.0:8080:80"`, "admin": `"127.0.0.1:8080:81"`}, 1},
+		{"tcp and udp", map[string]string{"web": `"8080:80"`, "admin": `"8080:81/udp"`}, 0},
+		{"other port", map[string]string{"web": `"8080:80"`, "admin": `"8081:80"`}, 0},
+		{"range docker picks from", map[string]string{"web": `"8080:80"`, "admin": `"8000-9000:81"`}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "services:\n"
+			for _, service := range []string{"web", "admin"} {
+				data += "  " + service + ":\n    ports:\n      - " + tt.ports[service] + "\n"
+			}
+			project, err := Parse([]byte(data), nil)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			web := project.service("web")
+			if got := project.ClashesWith(web.Ports[0]); len(got) != tt.want {
+				t.Errorf("ClashesWith() = %+v, want %d clash(es)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClashesWithinOneService(t *testing.T) {
+	data := "services:\n  web:\n    ports:\n      - \"8080:80\"\n      - \"8080:443\"\n"
+	project, err := Parse([]byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := project.service("web")
+	clashes := project.ClashesWith(web.Ports[0])
+	if len(clashes) != 1 || clashes[0].Service != "web" || clashes[0].Port.Line != 5 {
+		t.Errorf("ClashesWith() = %+v, want web's entry on line 5", clashes)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"docker-compose.yml":          "services: {}\n",
+		"docker-compose.override.yml": "services: {}\n",
+		"src/app/main.go":             "package main\n",
+	})
+
+	files, err := Find(filepath.Join(dir, "src", "app"))
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "docker-compose.yml"), filepath.Join(dir, "docker-compose.override.yml")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Find() = %v, want %v", files, want)
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	tests := map[string]string{"My App": "myapp", "shop_v2": "shop_v2", "web.dev": "webdev"}
+	for dir, want := range tests {
+		if got := projectName(dir); got != want {
+			t.Errorf("projectName(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
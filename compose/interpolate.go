@@ -0,0 +1,149 @@
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// Environment returns the variables compose files are interpolated with:
+// the .env file in dir, overridden by the process environment
+func Environment(dir string) map[string]string {
+	env := make(map[string]string)
+
+	if data, err := os.ReadFile(filepath.Join(dir, ".env")); err == nil {
+		lines := bufio.NewScanner(bytes.NewReader(data))
+		for lines.Scan() {
+			line := strings.TrimSpace(lines.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			env[strings.TrimSpace(key)] = value
+		}
+	}
+
+	for _, variable := range os.Environ() {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// Interpolate substitutes $VAR, ${VAR} and the ${VAR:-default},
+// ${VAR-default}, ${VAR:?error}, ${VAR?error}, ${VAR:+alt} and ${VAR+alt}
+// forms. $$ is a literal dollar sign.
+func Interpolate(value string, env map[string]string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		rest := value[i+1:]
+		switch {
+		case rest[0] == '$':
+			sb.WriteByte('$')
+			i++
+		case rest[0] == '{':
+			end := closingBrace(rest)
+			if end == -1 {
+				return "", fmt.Errorf("unterminated variable in %q", value)
+			}
+			expanded, err := expand(rest[1:end], env)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+			i += end + 1
+		default:
+			name := variableName.FindString(rest)
+			if name == "" {
+				sb.WriteByte('$')
+				continue
+			}
+			sb.WriteString(env[name])
+			i += len(name)
+		}
+	}
+	return sb.String(), nil
+}
+
+// closingBrace finds the "}" matching the "{" s starts with, so defaults
+// may contain variables themselves
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expand evaluates the inside of ${...}
+func expand(expr string, env map[string]string) (string, error) {
+	name := variableName.FindString(expr)
+	if name == "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+	value, set := env[name]
+	op := expr[len(name):]
+	if op == "" {
+		return value, nil
+	}
+
+	// Operators with a colon also treat an empty value as unset
+	nonEmpty := set
+	if strings.HasPrefix(op, ":") {
+		nonEmpty = set && value != ""
+		op = op[1:]
+	}
+	if op == "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+
+	arg := op[1:]
+	switch op[0] {
+	case '-':
+		if nonEmpty {
+			return value, nil
+		}
+		return Interpolate(arg, env)
+	case '?':
+		if nonEmpty {
+			return value, nil
+		}
+		if arg == "" {
+			arg = "not set"
+		}
+		return "", fmt.Errorf("required variable %s: %s", name, arg)
+	case '+':
+		if nonEmpty {
+			return Interpolate(arg, env)
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid variable ${%s}", expr)
+}
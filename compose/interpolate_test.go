@@ -0,0 +1,91 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"PORT": "8080", "EMPTY": "", "HOST": "127.0.0.1"}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string // substring of the error, "" for none
+	}{
+		{value: "8080", want: "8080"},
+		{value: "$PORT", want: "8080"},
+		{value: "${PORT}:80", want: "8080:80"},
+		{value: "$HOST:${PORT}:80", want: "127.0.0.1:8080:80"},
+		{value: "${UNSET}", want: ""},
+		{value: "${UNSET:-3000}", want: "3000"},
+		{value: "${EMPTY:-3000}", want: "3000"},
+		{value: "${EMPTY-3000}", want: ""},
+		{value: "${UNSET-3000}", want: "3000"},
+		{value: "${PORT:-3000}", want: "8080"},
+		{value: "${UNSET:-${PORT}}", want: "8080"},
+		{value: "${PORT:?set it}", want: "8080"},
+		{value: "${EMPTY?set it}", want: ""},
+		{value: "${UNSET:?set it}", wantErr: "required variable UNSET: set it"},
+		{value: "${EMPTY:?}", wantErr: "required variable EMPTY: not set"},
+		{value: "${UNSET?}", wantErr: "required variable UNSET"},
+		{value: "${PORT:+9090}", want: "9090"},
+		{value: "${EMPTY+9090}", want: "9090"},
+		{value: "${EMPTY:+9090}", want: ""},
+		{value: "$$PORT", want: "$PORT"},
+		{value: "$${PORT}", want: "${PORT}"},
+		{value: "cost: $5", want: "cost: $5"},
+		{value: "trailing $", want: "trailing $"},
+		{value: "${PORT", wantErr: "unterminated variable"},
+		{value: "${1PORT}", wantErr: "invalid variable"},
+		{value: "${PORT:}", wantErr: "invalid variable"},
+		{value: "${PORT*x}", wantErr: "invalid variable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Interpolate(tt.value, env)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Interpolate(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Interpolate(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	dotEnv := "# ports\nPORTSCANNER_TEST_WEB=3000\nexport PORTSCANNER_TEST_API = 4000\n" +
+		"PORTSCANNER_TEST_QUOTED=\"5000\"\nPORTSCANNER_TEST_SINGLE='6000'\nPORTSCANNER_TEST_SHADOWED=7000\nnot a variable\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(dotEnv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORTSCANNER_TEST_SHADOWED", "7001")
+
+	env := Environment(dir)
+	want := map[string]string{
+		"PORTSCANNER_TEST_WEB":      "3000",
+		"PORTSCANNER_TEST_API":      "4000",
+		"PORTSCANNER_TEST_QUOTED":   "5000",
+		"PORTSCANNER_TEST_SINGLE":   "6000",
+		"PORTSCANNER_TEST_SHADOWED": "7001", // the environment wins over .env
+	}
+	got := make(map[string]string)
+	for key := range want {
+		got[key] = env[key]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Environment() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package compose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"portscanner/scanner"
+)
+
+// parsePorts reads one ports: entry, either the short syntax
+// "[HOST_IP:][HOST:]CONTAINER[/PROTOCOL]" or the long syntax mapping.
+// Entries without a host port publish on a random one and yield nothing.
+func parsePorts(entry *yaml.Node, env map[string]string) ([]Port, error) {
+	var hostIP, published, target, protocol string
+
+	switch entry.Kind {
+	case yaml.ScalarNode:
+		value, err := Interpolate(entry.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		hostIP, published, target, protocol, err = splitShortSyntax(value)
+		if err != nil {
+			return nil, err
+		}
+	case yaml.MappingNode:
+		fields := map[string]*string{"host_ip": &hostIP, "published": &published, "target": &target, "protocol": &protocol}
+		for key, field := range fields {
+			node := lookup(entry, key)
+			if node == nil {
+				continue
+			}
+			value, err := Interpolate(node.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			*field = value
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ports entry")
+	}
+
+	switch protocol = strings.ToLower(protocol); protocol {
+	case "":
+		protocol = scanner.ProtocolTCP
+	case scanner.ProtocolTCP, scanner.ProtocolUDP:
+	default:
+		// sctp has no listener the scanner could find
+		return nil, nil
+	}
+	if published == "" {
+		return nil, nil
+	}
+
+	start, end, err := parseRange(published)
+	if err != nil {
+		return nil, err
+	}
+	targetStart, targetEnd, err := parseRange(target)
+	if err != nil {
+		return nil, err
+	}
+	hostIP = strings.Trim(hostIP, "[]")
+
+	switch {
+	case targetStart == targetEnd && start != end:
+		// "8000-8010:80" needs any one free host port of the range
+		return []Port{{
+			HostIP:   hostIP,
+			Spec:     scanner.PortSpec{Port: start, Protocol: protocol},
+			RangeEnd: end,
+			Target:   target,
+			Line:     entry.Line,
+		}}, nil
+	case targetEnd-targetStart != end-start:
+		return nil, fmt.Errorf("host range %s and container range %s differ in size", published, target)
+	}
+
+	// "9090-9091:8080-8081" maps host ports onto container ports pairwise
+	var ports []Port
+	for port := start; port <= end; port++ {
+		portTarget := target
+		if start != end {
+			portTarget = strconv.Itoa(targetStart + port - start)
+		}
+		ports = append(ports, Port{
+			HostIP: hostIP,
+			Spec:   scanner.PortSpec{Port: port, Protocol: protocol},
+			Target: portTarget,
+			Line:   entry.Line,
+		})
+	}
+	return ports, nil
+}
+
+// splitShortSyntax splits "127.0.0.1:8080:80/udp" and "[::1]:8080:80" into
+// their parts. published is "" for "80" and "127.0.0.1::80".
+func splitShortSyntax(value string) (hostIP, published, target, protocol string, err error) {
+	mapping, protocol, _ := strings.Cut(value, "/")
+
+	// A bracketed IPv6 host IP may itself contain colons
+	if strings.HasPrefix(mapping, "[") {
+		end := strings.Index(mapping, "]:")
+		if end == -1 {
+			return "", "", "", "", fmt.Errorf("invalid port mapping %q", value)
+		}
+		hostIP, mapping = mapping[:end+1], mapping[end+2:]
+	}
+
+	parts := strings.Split(mapping, ":")
+	switch {
+	case len(parts) == 1:
+		target = parts[0]
+	case len(parts) == 2:
+		published, target = parts[0], parts[1]
+	case hostIP == "":
+		// Unbracketed IPv6 host IPs are accepted too
+		hostIP = strings.Join(parts[:len(parts)-2], ":")
+		published, target = parts[len(parts)-2], parts[len(parts)-1]
+	default:
+		return "", "", "", "", fmt.Errorf("invalid port mapping %q", value)
+	}
+	return hostIP, published, target, protocol, nil
+}
+
+// parseRange reads "8080" or "8080-8081"
+func parseRange(value string) (int, int, error) {
+	startStr, endStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		endStr = startStr
+	}
+	start, err1 := strconv.Atoi(startStr)
+	end, err2 := strconv.Atoi(endStr)
+	if err1 != nil || err2 != nil || start < 1 || end > 65535 || start > end {
+		return 0, 0, fmt.Errorf("invalid port %q", value)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,87 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"portscanner/scanner"
+)
+
+func TestParsePortEntries(t *testing.T) {
+	tcp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP} }
+	udp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolUDP} }
+
+	tests := []struct {
+		name    string
+		entry   string // one item of services.web.ports
+		want    []Port // HostIP, Spec, RangeEnd and Target; Line is always 4
+		wantErr bool
+	}{
+		{"container port only", `"80"`, nil, false},
+		{"host and container", `"8080:80"`, []Port{{Spec: tcp(8080), Target: "80"}}, false},
+		{"unquoted", `8080:80`, []Port{{Spec: tcp(8080), Target: "80"}}, false},
+		{"host ip and udp", `"127.0.0.1:8125:8125/udp"`, []Port{{HostIP: "127.0.0.1", Spec: udp(8125), Target: "8125"}}, false},
+		{"upper-case protocol", `"5353:5353/UDP"`, []Port{{Spec: udp(5353), Target: "5353"}}, false},
+		{"explicit tcp", `"9000:9000/tcp"`, []Port{{Spec: tcp(9000), Target: "9000"}}, false},
+		{"sctp is skipped", `"9000:9000/sctp"`, nil, false},
+		{"bracketed ipv6", `"[::1]:80:80"`, []Port{{HostIP: "::1", Spec: tcp(80), Target: "80"}}, false},
+		{"unbracketed ipv6", `"::1:9229:9229"`, []Port{{HostIP: "::1", Spec: tcp(9229), Target: "9229"}}, false},
+		{"host ip without host port", `"127.0.0.1::80"`, nil, false},
+		{"unterminated bracket", `"[::1:80:80"`, nil, true},
+		{"pairwise range", `"9090-9091:8080-8081"`, []Port{{Spec: tcp(9090), Target: "8080"}, {Spec: tcp(9091), Target: "8081"}}, false},
+		{"host range for one port", `"8000-9000:80"`, []Port{{Spec: tcp(8000), RangeEnd: 9000, Target: "80"}}, false},
+		{"mismatched ranges", `"8000-8001:80-82"`, nil, true},
+		{"container range for one host port", `"8080:80-81"`, nil, true},
+		{"reversed range", `"9001-9000:80"`, nil, true},
+		{"port out of range", `"70000:80"`, nil, true},
+		{"not a port", `"http:80"`, nil, true},
+		{"long syntax", `{target: 80, published: "8080", host_ip: 127.0.0.1, protocol: udp}`,
+			[]Port{{HostIP: "127.0.0.1", Spec: udp(8080), Target: "80"}}, false},
+		{"long syntax without published", `{target: 80}`, nil, false},
+		{"long syntax range", `{target: 80, published: "8000-8002"}`, []Port{{Spec: tcp(8000), RangeEnd: 8002, Target: "80"}}, false},
+		{"interpolated", `"${WEB_PORT:-3000}:3000"`, []Port{{Spec: tcp(3000), Target: "3000"}}, false},
+		{"required variable", `"${WEB_PORT:?set WEB_PORT}:3000"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "services:\n  web:\n    ports:\n      - " + tt.entry + "\n"
+			project, err := Parse([]byte(data), map[string]string{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse() = %+v, want an error", project.Services[0].Ports)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var want []Port
+			for _, port := range tt.want {
+				port.Line = 4
+				want = append(want, port)
+			}
+			if got := project.Services[0].Ports; !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() ports = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBindingCandidates(t *testing.T) {
+	single := Binding{Spec: scanner.PortSpec{Port: 8080, Protocol: scanner.ProtocolTCP}}
+	if got := single.Candidates(); len(got) != 1 || got[0] != single.Spec {
+		t.Errorf("Candidates() = %v, want [%v]", got, single.Spec)
+	}
+
+	ranged := Binding{Spec: scanner.PortSpec{Port: 8000, Protocol: scanner.ProtocolUDP}, RangeEnd: 8002}
+	want := []scanner.PortSpec{
+		{Port: 8000, Protocol: scanner.ProtocolUDP},
+		{Port: 8001, Protocol: scanner.ProtocolUDP},
+		{Port: 8002, Protocol: scanner.ProtocolUDP},
+	}
+	if got := ranged.Candidates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates() = %v, want %v", got, want)
+	}
+}
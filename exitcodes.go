@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portscanner/scanner"
+)
+
+// Exit codes, documented in the README. Scripts depend on them, so existing
+// values must not change.
+const (
+	exitOK        = 0 // every port is free (or matches its --expect)
+	exitOccupied  = 1 // a port is occupied, or an --expect assertion failed
+	exitUsage     = 2 // invalid flags, ports or format (same code the flag package uses)
+	exitScanError = 3 // at least one port could not be checked
+)
+
+// Expected port states for --expect
+const (
+	expectFree  = "free"
+	expectInUse = "in-use"
+)
+
+// expectFlag collects repeated --expect PORT[/PROTO]=STATE flags
+type expectFlag map[scanner.PortSpec]string
+
+func (ef expectFlag) String() string {
+	var parts []string
+	for spec, state := range ef {
+		parts = append(parts, fmt.Sprintf("%s=%s", spec, state))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (ef expectFlag) Set(value string) error {
+	specStr, state, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected PORT=free or PORT=in-use, got %q", value)
+	}
+
+	portStr, protocol, err := splitProtocol(specStr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port in --expect: %s", portStr)
+	}
+
+	switch state {
+	case expectFree:
+	case expectInUse, "used", "up":
+		state = expectInUse
+	default:
+		return fmt.Errorf("invalid state in --expect: %s (use free or in-use)", state)
+	}
+
+	ef[scanner.PortSpec{Port: port, Protocol: protocol}] = state
+	return nil
+}
+
+// addExpectedPorts appends ports that only appear in --expect
+func addExpectedPorts(ports []scanner.PortSpec, expectations expectFlag) []scanner.PortSpec {
+	listed := make(map[scanner.PortSpec]bool, len(ports))
+	for _, spec := range ports {
+		listed[spec] = true
+	}
+
+	var extra []scanner.PortSpec
+	for spec := range expectations {
+		if !listed[spec] {
+			extra = append(extra, spec)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		if extra[i].Port != extra[j].Port {
+			return extra[i].Port < extra[j].Port
+		}
+		return extra[i].Protocol < extra[j].Protocol
+	})
+	return append(ports, extra...)
+}
+
+// exitCode maps scan results to the documented exit codes. A port without
+// an --expect entry is expected to be free, unless the manifest marks it
+// optional. exitUsage (a --bind address this host doesn't have) takes
+// precedence over exitScanError, which takes precedence over exitOccupied:
+// a run that couldn't check every port can't vouch for the others.
+func exitCode(statuses []*scanner.PortStatus, expectations expectFlag) int {
+	code := exitOK
+	badBind := false
+
+	for _, status := range statuses {
+		expected, explicit := expectations[status.Spec()]
+		optional := !explicit && status.Optional
+
+		if status.Reason == scanner.ReasonAddressNotAvailable {
+			badBind = true
+			continue
+		}
+
+		// An unknown owner doesn't matter when any owner will do
+		ownerIrrelevant := status.ErrorCode == scanner.ErrCodeOwnerLookup && (optional || expected == expectInUse)
+		if checkFailed(status) && !ownerIrrelevant {
+			code = exitScanError
+			continue
+		}
+		if optional {
+			continue
+		}
+		// Without the right to bind it, whether anything listens is unknown
+		// (the scanner reports in_use when the socket table shows a listener)
+		if status.Reason == scanner.ReasonPermissionDenied {
+			code = exitScanError
+			continue
+		}
+		if expected == "" {
+			expected = expectFree
+		}
+
+		actual := portState(status)
+
+		if actual != expected {
+			if explicit {
+				fmt.Fprintf(os.Stderr, "❌ Port %s: expected %s, but it is %s\n", status.Spec(), expected, actual)
+			}
+			if code == exitOK {
+				code = exitOccupied
+			}
+		}
+	}
+
+	if badBind {
+		return exitUsage
+	}
+	return code
+}
+
+// portState is what a port is for --expect: free, in-use when a listener
+// holds it, or unavailable when only e.g. TIME_WAIT connections block it
+func portState(status *scanner.PortStatus) string {
+	switch {
+	case status.IsAvailable:
+		return expectFree
+	case status.Reason == scanner.ReasonInUse:
+		return expectInUse
+	}
+	return "unavailable"
+}
+
+// checkFailed reports whether status's port couldn't be fully checked: the
+// scan failed, the bind failed for an unknown reason, or the port is taken
+// by an owner we couldn't identify
+func checkFailed(status *scanner.PortStatus) bool {
+	switch {
+	case status.ErrorCode == scanner.ErrCodeScanFailed, status.Reason == scanner.ReasonBindFailed:
+		return true
+	case status.ErrorCode == scanner.ErrCodeOwnerLookup:
+		// The container publishing the port identifies it well enough
+		return status.Container == nil
+	}
+	return false
+}
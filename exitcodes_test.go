@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"portscanner/scanner"
+)
+
+func TestExitCode(t *testing.T) {
+	tcp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP} }
+	free := &scanner.PortStatus{Port: 3000, Protocol: scanner.ProtocolTCP, IsAvailable: true}
+	occupied := &scanner.PortStatus{Port: 5432, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonInUse, PID: 880}
+	hiddenOwner := &scanner.PortStatus{Port: 53, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonInUse, ErrorCode: scanner.ErrCodeOwnerLookup, Error: "owned by uid 101"}
+	container := &scanner.PortStatus{Port: 8080, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonInUse, ErrorCode: scanner.ErrCodeOwnerLookup, Container: &scanner.Container{Name: "web"}}
+	bindFailed := &scanner.PortStatus{Port: 8125, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonBindFailed, BindError: "no buffer space available"}
+	scanFailed := &scanner.PortStatus{Port: 6379, Protocol: scanner.ProtocolTCP, ErrorCode: scanner.ErrCodeScanFailed}
+	denied := &scanner.PortStatus{Port: 80, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonPermissionDenied}
+	badBind := &scanner.PortStatus{Port: 5432, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonAddressNotAvailable}
+	timeWait := &scanner.PortStatus{Port: 8000, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonTimeWait}
+	optionalDenied := &scanner.PortStatus{Port: 443, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonPermissionDenied, Optional: true}
+	optional := &scanner.PortStatus{Port: 9000, Protocol: scanner.ProtocolTCP, Reason: scanner.ReasonInUse, ErrorCode: scanner.ErrCodeOwnerLookup, Optional: true}
+
+	tests := []struct {
+		name         string
+		statuses     []*scanner.PortStatus
+		expectations expectFlag
+		want         int
+	}{
+		{"all free", []*scanner.PortStatus{free}, nil, exitOK},
+		{"occupied", []*scanner.PortStatus{free, occupied}, nil, exitOccupied},
+		{"privileged port", []*scanner.PortStatus{denied}, nil, exitScanError},
+		{"privileged port expected in use", []*scanner.PortStatus{denied}, expectFlag{tcp(80): expectInUse}, exitScanError},
+		{"optional privileged port", []*scanner.PortStatus{optionalDenied}, nil, exitOK},
+		{"address not available", []*scanner.PortStatus{badBind}, nil, exitUsage},
+		{"address not available, expected in use", []*scanner.PortStatus{badBind}, expectFlag{tcp(5432): expectInUse}, exitUsage},
+		{"bad bind wins over scan errors", []*scanner.PortStatus{scanFailed, badBind, occupied}, nil, exitUsage},
+		{"time wait isn't free", []*scanner.PortStatus{timeWait}, nil, exitOccupied},
+		{"time wait isn't in use", []*scanner.PortStatus{timeWait}, expectFlag{tcp(8000): expectInUse}, exitOccupied},
+		{"expected in use", []*scanner.PortStatus{occupied}, expectFlag{tcp(5432): expectInUse}, exitOK},
+		{"expected in use but free", []*scanner.PortStatus{free}, expectFlag{tcp(3000): expectInUse}, exitOccupied},
+		{"unknown bind error", []*scanner.PortStatus{bindFailed}, nil, exitScanError},
+		{"scan failed", []*scanner.PortStatus{scanFailed}, nil, exitScanError},
+		{"owner lookup failed", []*scanner.PortStatus{hiddenOwner}, nil, exitScanError},
+		{"owner lookup failed, any owner expected", []*scanner.PortStatus{hiddenOwner}, expectFlag{tcp(53): expectInUse}, exitOK},
+		{"owner lookup failed on an optional port", []*scanner.PortStatus{optional}, nil, exitOK},
+		{"container identifies the owner", []*scanner.PortStatus{container}, nil, exitOccupied},
+		{"scan error wins over occupied", []*scanner.PortStatus{occupied, bindFailed}, nil, exitScanError},
+		{"scan error wins when it comes first", []*scanner.PortStatus{scanFailed, occupied}, nil, exitScanError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.statuses, tt.expectations); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package fixer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"portscanner/formatter"
+	"portscanner/scanner"
+)
+
+// Policies for non-interactive (--yes) runs
+const (
+	PolicyRemap = "remap" // suggest a verified free port, never touch processes
+	PolicyStop  = "stop"  // gracefully stop every process holding the port
+	PolicySkip  = "skip"  // report only
+)
+
+// Actions taken for a conflict
+const (
+	ActionRemap = "remap"
+	ActionStop  = "stop"
+	ActionSkip  = "skip"
+)
+
+// ValidPolicy reports whether policy is one of the Policy constants
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case PolicyRemap, PolicyStop, PolicySkip:
+		return true
+	}
+	return false
+}
+
+type Options struct {
+	DryRun bool   // print what would happen without doing it
+	Yes    bool   // don't prompt, apply Policy to every conflict
+	Policy string // one of the Policy constants
+	// StopParent also stops the process that launched the holders, e.g.
+	// npm for node. Without it only listeners and workers are signalled.
+	StopParent bool
+	In         io.Reader
+	Out        io.Writer
+}
+
+// Result records what was done for one conflict
+type Result struct {
+	Port    int
+	Action  string
+	NewPort int // remap target
+	PID     int // owning process; with ActionStop, every target of stopOrder was signalled
+	Applied bool
+	Err     error
+}
+
+type Fixer struct {
+	scanner     scanner.PortScanner
+	assessor    *formatter.DetailedFormatter
+	in          *bufio.Reader
+	out         io.Writer
+	dryRun      bool
+	yes         bool
+	policy      string
+	stopParent  bool
+	stopTimeout time.Duration
+	ports       *scanner.AlternativePortFinder
+}
+
+func NewFixer(ps scanner.PortScanner, opts Options) *Fixer {
+	in := opts.In
+	if in == nil {
+		in = os.Stdin
+	}
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	policy := opts.Policy
+	if policy == "" {
+		policy = PolicyRemap
+	}
+
+	return &Fixer{
+		scanner:     ps,
+		assessor:    formatter.NewDetailedFormatter(ps.BindAddress()),
+		in:          bufio.NewReader(in),
+		out:         out,
+		dryRun:      opts.DryRun,
+		yes:         opts.Yes,
+		policy:      policy,
+		stopParent:  opts.StopParent,
+		stopTimeout: 10 * time.Second,
+		ports:       scanner.NewAlternativePortFinder(ps.BindAddress()),
+	}
+}
+
+// Run walks through every occupied port. Statuses of ports that were freed
+// are refreshed in place so callers can recompute the exit code.
+func (f *Fixer) Run(statuses []*scanner.PortStatus) []Result {
+	var results []Result
+
+	fmt.Fprintln(f.out, "")
+	if f.dryRun {
+		fmt.Fprintln(f.out, "🧪 CONFLICT RESOLUTION (dry run - nothing will be changed)")
+	} else {
+		fmt.Fprintln(f.out, "🔧 CONFLICT RESOLUTION")
+	}
+
+	// Never remap onto a port that is part of this scan
+	for _, status := range statuses {
+		f.ports.Reserve(status.Port)
+	}
+
+	for _, status := range statuses {
+		if status.IsAvailable {
+			continue
+		}
+
+		f.describe(status)
+		action := f.chooseAction(status)
+		result := f.apply(status, action)
+		results = append(results, result)
+
+		if result.Action == ActionStop && result.Applied {
+			if fresh, err := f.check(status.Spec()); err == nil {
+				*status = *fresh
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(f.out, "• No conflicts to resolve ✅")
+	}
+	return results
+}
+
+func (f *Fixer) describe(status *scanner.PortStatus) {
+	if status.Reason != "" && status.Reason != scanner.ReasonInUse {
+		reason, remedy := formatter.ExplainReason(status)
+		fmt.Fprintf(f.out, "\n⚠️  Port %s can't be bound: %s\n", status.Spec(), reason)
+		fmt.Fprintf(f.out, "   Remedy: %s\n", remedy)
+		return
+	}
+
+	owner := "unknown process"
+	if status.PID != 0 {
+		owner = fmt.Sprintf("%s (PID %d)", status.ProcessName, status.PID)
+	}
+	if container := status.Container; container != nil {
+		owner = fmt.Sprintf("container %s (%s) via %s", container.Name, container.Image, owner)
+	}
+
+	fmt.Fprintf(f.out, "\n⚠️  Port %s is held by %s\n", status.Spec(), owner)
+	fmt.Fprintf(f.out, "   Impact: %s\n", f.assessor.AssessImpact(status))
+	fmt.Fprintf(f.out, "   Risk if stopped: %s\n", f.assessor.AssessRisk(status))
+	if status.PID != 0 && status.Container == nil {
+		fmt.Fprintf(f.out, "   Stop would signal: %s\n", describeOwners(stopOrder(status, f.stopParent)))
+	}
+}
+
+func (f *Fixer) chooseAction(status *scanner.PortStatus) string {
+	if f.yes {
+		action := f.policy
+		if action == PolicyStop && status.PID == 0 {
+			// Nothing to stop when the owner is unknown
+			action = PolicySkip
+		}
+		fmt.Fprintf(f.out, "   Policy: %s\n", action)
+		return action
+	}
+
+	for {
+		if status.PID != 0 {
+			fmt.Fprint(f.out, "   Action? [r]emap, [s]top, s[k]ip (default: skip): ")
+		} else {
+			fmt.Fprint(f.out, "   Action? [r]emap, s[k]ip (default: skip): ")
+		}
+
+		line, err := f.in.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		switch {
+		case answer == "r" || answer == "remap":
+			return ActionRemap
+		case (answer == "s" || answer == "stop") && status.PID != 0:
+			return ActionStop
+		case answer == "" || answer == "k" || answer == "skip":
+			return ActionSkip
+		}
+		if err != nil { // EOF: nobody left to ask
+			fmt.Fprintln(f.out, "")
+			return ActionSkip
+		}
+		fmt.Fprintf(f.out, "   Unknown choice: %s\n", answer)
+	}
+}
+
+func (f *Fixer) apply(status *scanner.PortStatus, action string) Result {
+	result := Result{Port: status.Port, Action: action}
+
+	switch action {
+	case ActionRemap:
+		f.remap(status, &result)
+	case ActionStop:
+		f.stop(status, &result)
+	default:
+		fmt.Fprintln(f.out, "   ⏭️  Skipped")
+	}
+	return result
+}
+
+func (f *Fixer) remap(status *scanner.PortStatus, result *Result) {
+	newPort, err := f.ports.Find(status.Spec())
+	if err != nil {
+		result.Err = err
+		fmt.Fprintf(f.out, "   ❌ %v\n", err)
+		return
+	}
+
+	result.NewPort = newPort
+	if f.dryRun {
+		fmt.Fprintf(f.out, "   [dry-run] Would remap %d → %d\n", status.Port, newPort)
+		return
+	}
+
+	result.Applied = true
+	fmt.Fprintf(f.out, "   ✅ Remap %d → %d (verified free)\n", status.Port, newPort)
+	fmt.Fprintf(f.out, "      Start your service with PORT=%d or update its configuration\n", newPort)
+}
+
+func (f *Fixer) stop(status *scanner.PortStatus, result *Result) {
+	result.PID = status.PID
+	if status.Container != nil {
+		// Killing docker-proxy would leave the container running without its port
+		result.Err = fmt.Errorf("port %d is published by container %s", status.Port, status.Container.Name)
+		fmt.Fprintf(f.out, "   ❌ %v, stop it with: docker stop %s\n", result.Err, status.Container.Name)
+		return
+	}
+
+	var targets []scanner.Owner
+	for _, owner := range stopOrder(status, f.stopParent) {
+		if owner.PID <= 1 || owner.PID == os.Getpid() {
+			fmt.Fprintf(f.out, "   ⚠️  Refusing to stop PID %d\n", owner.PID)
+			continue
+		}
+		targets = append(targets, owner)
+	}
+	if len(targets) == 0 {
+		result.Err = fmt.Errorf("refusing to stop PID %d", status.PID)
+		fmt.Fprintf(f.out, "   ❌ %v\n", result.Err)
+		return
+	}
+
+	if f.dryRun {
+		fmt.Fprintf(f.out, "   [dry-run] Would send SIGTERM to %s\n", describeOwners(targets))
+		return
+	}
+
+	signalled := 0
+	for _, owner := range targets {
+		process, err := os.FindProcess(owner.PID)
+		if err == nil {
+			err = process.Signal(syscall.SIGTERM)
+		}
+		if errors.Is(err, os.ErrProcessDone) {
+			// Already gone, e.g. a worker its master stopped
+			continue
+		}
+		if err != nil {
+			result.Err = err
+			fmt.Fprintf(f.out, "   ❌ Could not stop PID %d: %v\n", owner.PID, err)
+			continue
+		}
+		signalled++
+	}
+	if signalled == 0 && result.Err != nil {
+		return
+	}
+
+	if f.waitForPort(status.Spec()) {
+		result.Applied = true
+		result.Err = nil
+		fmt.Fprintf(f.out, "   ✅ Stopped %s, port %d is free\n", describeOwners(targets), status.Port)
+		return
+	}
+
+	result.Err = fmt.Errorf("port %d still in use after %s", status.Port, f.stopTimeout)
+	fmt.Fprintf(f.out, "   ⚠️  Sent SIGTERM but port %d is still in use after %s\n", status.Port, f.stopTimeout)
+	fmt.Fprintf(f.out, "      Check the processes or stop them manually: kill %s\n", joinPIDs(targets))
+}
+
+// stopOrder lists the processes holding the port, listeners before
+// workers so a pre-fork master shuts its workers down cleanly. The parent
+// doesn't hold the port and may be an IDE or a supervisor, so it is only
+// included, first, with withParent: a launcher like npm would otherwise
+// respawn or outlive its child.
+func stopOrder(status *scanner.PortStatus, withParent bool) []scanner.Owner {
+	if len(status.Owners) == 0 {
+		return []scanner.Owner{{PID: status.PID, Name: status.ProcessName, Relationship: scanner.RelationListener}}
+	}
+
+	rank := map[string]int{scanner.RelationParent: 0, scanner.RelationListener: 1, scanner.RelationWorker: 2}
+	var owners []scanner.Owner
+	for _, owner := range status.Owners {
+		if owner.Relationship != scanner.RelationParent || withParent {
+			owners = append(owners, owner)
+		}
+	}
+	sort.SliceStable(owners, func(i, j int) bool {
+		return rank[owners[i].Relationship] < rank[owners[j].Relationship]
+	})
+	return owners
+}
+
+// describeOwners renders "npm (PID 4100), node (PID 4120)"
+func describeOwners(owners []scanner.Owner) string {
+	parts := make([]string, len(owners))
+	for i, owner := range owners {
+		parts[i] = fmt.Sprintf("%s (PID %d)", owner.Name, owner.PID)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinPIDs(owners []scanner.Owner) string {
+	pids := make([]string, len(owners))
+	for i, owner := range owners {
+		pids[i] = strconv.Itoa(owner.PID)
+	}
+	return strings.Join(pids, " ")
+}
+
+// waitForPort polls until the port can be bound or the stop timeout expires
+func (f *Fixer) waitForPort(spec scanner.PortSpec) bool {
+	deadline := time.Now().Add(f.stopTimeout)
+	for time.Now().Before(deadline) {
+		status, err := f.check(spec)
+		if err == nil && status.IsAvailable {
+			return true
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return false
+}
+
+func (f *Fixer) check(spec scanner.PortSpec) (*scanner.PortStatus, error) {
+	statuses, err := f.scanner.CheckPorts(context.Background(), []scanner.PortSpec{spec})
+	if err != nil {
+		return nil, err
+	}
+	return statuses[0], nil
+}
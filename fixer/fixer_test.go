@@ -0,0 +1,81 @@
+package fixer
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"portscanner/scanner"
+)
+
+func TestStopOrder(t *testing.T) {
+	npm := scanner.Owner{PID: 4100, Name: "npm", Relationship: scanner.RelationParent}
+	master := scanner.Owner{PID: 4120, Name: "gunicorn", Relationship: scanner.RelationListener}
+	worker1 := scanner.Owner{PID: 4121, Name: "gunicorn", Relationship: scanner.RelationWorker}
+	worker2 := scanner.Owner{PID: 4122, Name: "gunicorn", Relationship: scanner.RelationWorker}
+	// resolveOwners orders listeners, workers, parent
+	owned := &scanner.PortStatus{PID: 4120, ProcessName: "gunicorn", Owners: []scanner.Owner{master, worker1, worker2, npm}}
+
+	tests := []struct {
+		name       string
+		status     *scanner.PortStatus
+		withParent bool
+		want       []int
+	}{
+		{"holders only", owned, false, []int{4120, 4121, 4122}},
+		{"parent first when asked", owned, true, []int{4100, 4120, 4121, 4122}},
+		{"no parent to add", &scanner.PortStatus{PID: 4120, Owners: []scanner.Owner{master, worker1}}, true, []int{4120, 4121}},
+		{"owners unknown", &scanner.PortStatus{PID: 880, ProcessName: "postgres"}, true, []int{880}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, owner := range stopOrder(tt.status, tt.withParent) {
+				got = append(got, owner.PID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("stopOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChooseAction(t *testing.T) {
+	owned := &scanner.PortStatus{Port: 3000, PID: 4120, ProcessName: "node"}
+	unowned := &scanner.PortStatus{Port: 53, Reason: scanner.ReasonInUse}
+
+	tests := []struct {
+		name   string
+		status *scanner.PortStatus
+		yes    bool
+		policy string
+		input  string
+		want   string
+	}{
+		{"remap", owned, false, "", "r\n", ActionRemap},
+		{"stop", owned, false, "", "stop\n", ActionStop},
+		{"enter skips", owned, false, "", "\n", ActionSkip},
+		{"asks again after an unknown choice", owned, false, "", "x\nS\n", ActionStop},
+		{"no stop without an owner", unowned, false, "", "s\nr\n", ActionRemap},
+		{"EOF skips", owned, false, "", "", ActionSkip},
+		{"policy", owned, true, PolicyStop, "", ActionStop},
+		{"stop policy skips an unknown owner", unowned, true, PolicyStop, "", ActionSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Fixer{
+				in:     bufio.NewReader(strings.NewReader(tt.input)),
+				out:    io.Discard,
+				yes:    tt.yes,
+				policy: tt.policy,
+			}
+			if got := f.chooseAction(tt.status); got != tt.want {
+				t.Errorf("chooseAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
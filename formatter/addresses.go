@@ -0,0 +1,28 @@
+package formatter
+
+import (
+	"portscanner/scanner"
+	"strings"
+)
+
+// describeFamily flags listeners that only one IP version can reach, the
+// usual cause of "localhost works in curl but not in the browser"
+func describeFamily(status *scanner.PortStatus) string {
+	switch status.Family {
+	case scanner.FamilyIPv4:
+		return "IPv4 only"
+	case scanner.FamilyIPv6:
+		return "IPv6 only"
+	case scanner.FamilyDual:
+		return "IPv4+IPv6"
+	}
+	return "-"
+}
+
+// describeAddresses renders "127.0.0.1, ::1 (IPv4+IPv6)"
+func describeAddresses(status *scanner.PortStatus) string {
+	if len(status.Addresses) == 0 {
+		return "unknown"
+	}
+	return strings.Join(status.Addresses, ", ") + " (" + describeFamily(status) + ")"
+}
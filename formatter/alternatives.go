@@ -0,0 +1,49 @@
+package formatter
+
+import (
+	"fmt"
+	"portscanner/scanner"
+	"strconv"
+)
+
+// suggestAlternatives finds a verified free port for every conflict in the
+// report. Ports that are part of the report are never suggested, and no port
+// is suggested for two conflicts. Suggestions are free on bindAddress, or on
+// every address when it is empty.
+func suggestAlternatives(statuses []*scanner.PortStatus, bindAddress string) map[scanner.PortSpec]int {
+	finder := scanner.NewAlternativePortFinder(bindAddress)
+	for _, status := range statuses {
+		finder.Reserve(status.Port)
+	}
+
+	alternatives := make(map[scanner.PortSpec]int)
+	for _, status := range statuses {
+		if status.IsAvailable {
+			continue
+		}
+		if _, done := alternatives[status.Spec()]; done {
+			continue
+		}
+		// 0 means nothing free was found nearby
+		alternative, _ := finder.Find(status.Spec())
+		alternatives[status.Spec()] = alternative
+	}
+	return alternatives
+}
+
+// formatMapping renders "3000 → 3001 (available)"
+func formatMapping(spec scanner.PortSpec, alternative int) string {
+	if alternative == 0 {
+		return fmt.Sprintf("%s → no free port nearby", formatPort(spec))
+	}
+	alt := scanner.PortSpec{Port: alternative, Protocol: spec.Protocol}
+	return fmt.Sprintf("%s → %s (available)", formatPort(spec), formatPort(alt))
+}
+
+// formatPort renders TCP ports bare, as they always were, and others as "53/udp"
+func formatPort(spec scanner.PortSpec) string {
+	if spec.Protocol == "" || spec.Protocol == scanner.ProtocolTCP {
+		return strconv.Itoa(spec.Port)
+	}
+	return spec.String()
+}
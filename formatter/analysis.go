@@ -0,0 +1,146 @@
+package formatter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"portscanner/scanner"
+	"strings"
+)
+
+// describeAnalysis renders a process analysis as "node / web / ~/code/shop-frontend"
+func describeAnalysis(analysis *scanner.ProcessAnalysis) string {
+	if analysis == nil {
+		return "-"
+	}
+
+	var parts []string
+	if analysis.Technology != "" {
+		parts = append(parts, analysis.Technology)
+	}
+	if analysis.ServiceType != "" {
+		parts = append(parts, analysis.ServiceType)
+	}
+	if analysis.ProjectPath != "" {
+		parts = append(parts, describeProjectPath(analysis))
+	}
+
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " / ")
+}
+
+var frameworkNames = map[string]string{
+	"nextjs":      "Next.js",
+	"vite":        "Vite",
+	"django":      "Django",
+	"fastapi":     "FastAPI",
+	"flask":       "Flask",
+	"rails":       "Rails",
+	"spring-boot": "Spring Boot",
+}
+
+// describeFramework returns the display name of the detected framework
+func describeFramework(analysis *scanner.ProcessAnalysis) string {
+	if analysis == nil || analysis.Framework == "" {
+		return "-"
+	}
+	if name, ok := frameworkNames[analysis.Framework]; ok {
+		return name
+	}
+	return analysis.Framework
+}
+
+// describeTech renders "node / Next.js"
+func describeTech(analysis *scanner.ProcessAnalysis) string {
+	if analysis == nil || analysis.Technology == "" {
+		return "-"
+	}
+	if analysis.Framework == "" {
+		return analysis.Technology
+	}
+	return analysis.Technology + " / " + describeFramework(analysis)
+}
+
+// describeConfigFiles lists the project markers by file name only
+func describeConfigFiles(analysis *scanner.ProcessAnalysis) string {
+	if analysis == nil || len(analysis.ConfigFiles) == 0 {
+		return "none"
+	}
+
+	names := make([]string, len(analysis.ConfigFiles))
+	for i, file := range analysis.ConfigFiles {
+		names[i] = filepath.Base(file)
+	}
+	return strings.Join(names, ", ")
+}
+
+// serviceFromAnalysis returns the detected service type, or "" when the port
+// has no analyzed owner and callers should fall back to guessing
+func serviceFromAnalysis(status *scanner.PortStatus) string {
+	if status.Analysis == nil || status.Analysis.ServiceType == "" {
+		return ""
+	}
+	return status.Analysis.ServiceType
+}
+
+// describeProjectPath renders the project root as the process sees it,
+// e.g. "/app (container 4f2a9c1be0d7)" inside a container
+func describeProjectPath(analysis *scanner.ProcessAnalysis) string {
+	path := shortenPath(analysis.ProjectPath)
+	if analysis.RootDir != "" {
+		rel, err := filepath.Rel(analysis.RootDir, analysis.ProjectPath)
+		if err == nil {
+			path = filepath.Join("/", rel)
+		}
+	}
+	if analysis.ContainerID != "" {
+		return fmt.Sprintf("%s (container %s)", path, shortContainerID(analysis.ContainerID))
+	}
+	return path
+}
+
+// describeIsolation renders "docker 4f2a9c1be0d7, separate PID and mount
+// namespaces", or "" for a process that shares everything with us
+func describeIsolation(analysis *scanner.ProcessAnalysis) string {
+	var parts []string
+	if analysis.ContainerID != "" {
+		parts = append(parts, fmt.Sprintf("%s %s", analysis.ContainerRuntime, shortContainerID(analysis.ContainerID)))
+	}
+
+	var namespaces []string
+	if analysis.SeparatePIDNamespace {
+		namespaces = append(namespaces, "PID")
+	}
+	if analysis.SeparateMountNamespace {
+		namespaces = append(namespaces, "mount")
+	}
+	switch len(namespaces) {
+	case 1:
+		parts = append(parts, fmt.Sprintf("separate %s namespace", namespaces[0]))
+	case 2:
+		parts = append(parts, "separate PID and mount namespaces")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortContainerID shortens an ID like docker ps does
+func shortContainerID(id string) string {
+	return id[:min(12, len(id))]
+}
+
+// shortenPath replaces the user's home directory with "~"
+func shortenPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return filepath.Join("~", rest)
+	}
+	return path
+}
@@ -0,0 +1,149 @@
+package formatter
+
+import (
+	"fmt"
+	"net"
+	"portscanner/compose"
+	"portscanner/scanner"
+	"strings"
+)
+
+type ComposeFormatter struct{}
+
+func NewComposeFormatter() *ComposeFormatter {
+	return &ComposeFormatter{}
+}
+
+// ServiceTable reports every published port by compose service: whether the
+// host port is free, and which other entries publish it too
+func (cf *ComposeFormatter) ServiceTable(project *compose.Project, statuses map[compose.Binding]*scanner.PortStatus) string {
+	var sb strings.Builder
+
+	// Header
+	sb.WriteString(fmt.Sprintf("COMPOSE PREFLIGHT: %s\n", project.Name))
+	sb.WriteString("──────────────────────────────\n")
+	sb.WriteString(fmt.Sprintf("Files: %s\n", strings.Join(cf.displayFiles(project), ", ")))
+
+	var problems []string
+	published := 0
+	for _, service := range project.Services {
+		if len(service.Ports) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\n🐳 %s\n", service.Name))
+		sb.WriteString(cf.formatRow("PUBLISHED", "TARGET", "STATUS", "PROCESS", "DECLARED IN"))
+		sb.WriteString(cf.formatRow("─────────", "──────", "──────", "───────", "───────────"))
+		for _, port := range service.Ports {
+			published++
+			status := statuses[port.Binding()]
+			clashes := project.ClashesWith(port)
+
+			label, process := "-", "-"
+			if status != nil {
+				label = reasonLabel(status)
+				process = cf.formatProcess(status)
+			}
+			if status != nil && project.Runs(service.Name, status.Container) {
+				label = "🐳 RUNNING"
+			}
+			if len(clashes) > 0 && (status == nil || status.IsAvailable) {
+				label = "💥 CLASH"
+			}
+			sb.WriteString(cf.formatRow(describePublished(port), port.Target, label, process, port.Location()))
+
+			if problem := cf.describeProblem(project, service.Name, port, status, clashes); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	sb.WriteString("\n")
+	if published == 0 {
+		sb.WriteString("• No service publishes a host port\n")
+		return sb.String()
+	}
+	for _, problem := range problems {
+		sb.WriteString(problem)
+	}
+	if len(problems) > 0 {
+		sb.WriteString(fmt.Sprintf("• %d of %s can't be bound: docker compose up would fail 🚨\n",
+			len(problems), plural(published, "published port")))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("• All %s are free - docker compose up can bind them ✅\n", plural(published, "published port")))
+	return sb.String()
+}
+
+func (cf *ComposeFormatter) formatRow(published, target, status, process, location string) string {
+	return fmt.Sprintf("   %-24s %-8s %-13s %-18s %s\n", published, target, status, process, location)
+}
+
+func (cf *ComposeFormatter) formatProcess(status *scanner.PortStatus) string {
+	if status.Container != nil {
+		return status.Container.Name
+	}
+	if status.IsAvailable || bindFailed(status) || status.ProcessName == "" {
+		return "-"
+	}
+	if status.PID == 0 {
+		return status.ProcessName
+	}
+	return fmt.Sprintf("%s:%d%s", status.ProcessName, status.PID, extraOwners(status))
+}
+
+// describeProblem explains why port can't be published, "" when it can
+func (cf *ComposeFormatter) describeProblem(project *compose.Project, service string, port compose.Port, status *scanner.PortStatus, clashes []compose.Clash) string {
+	var reasons []string
+	switch {
+	case status == nil || status.IsAvailable:
+	case project.Runs(service, status.Container):
+		// docker compose up leaves it running or recreates it
+	case status.Reason == scanner.ReasonPermissionDenied:
+		// The docker daemon binds as root, unlike this scan
+	case status.ErrorCode == scanner.ErrCodeScanFailed:
+		reasons = append(reasons, fmt.Sprintf("could not be checked: %s", status.Error))
+	case bindFailed(status):
+		reason, _ := ExplainReason(status)
+		reasons = append(reasons, strings.ToLower(reason))
+	case status.Container != nil:
+		reasons = append(reasons, fmt.Sprintf("in use by container %s", describeContainer(status.Container)))
+	case status.ProcessName != "" && status.PID != 0:
+		reasons = append(reasons, fmt.Sprintf("in use by %s (PID %d)", status.ProcessName, status.PID))
+	default:
+		reasons = append(reasons, "already in use")
+	}
+	for _, clash := range clashes {
+		reasons = append(reasons, fmt.Sprintf("also published by %s (%s)", clash.Service, clash.Port.Location()))
+	}
+
+	if len(reasons) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("• %s %s: %s\n", service, describePublished(port), strings.Join(reasons, "; "))
+}
+
+func (cf *ComposeFormatter) displayFiles(project *compose.Project) []string {
+	files := make([]string, len(project.Files))
+	for i, file := range project.Files {
+		files[i] = shortenPath(file)
+	}
+	return files
+}
+
+// describePublished renders "8080", "127.0.0.1:5432", "[::1]:8125/udp" or
+// "8000-8010" for a range docker picks one port from
+func describePublished(port compose.Port) string {
+	published := formatPort(port.Spec)
+	if port.RangeEnd != 0 {
+		published = fmt.Sprintf("%d-%s", port.Spec.Port, formatPort(scanner.PortSpec{Port: port.RangeEnd, Protocol: port.Spec.Protocol}))
+	}
+	if port.HostIP == "" {
+		return published
+	}
+	host := port.HostIP
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		host = "[" + host + "]"
+	}
+	return host + ":" + published
+}
@@ -0,0 +1,31 @@
+package formatter
+
+import (
+	"fmt"
+	"path"
+	"portscanner/scanner"
+	"strings"
+)
+
+// describeContainer renders "shop-web-1 (nginx:1.25)"
+func describeContainer(container *scanner.Container) string {
+	return fmt.Sprintf("%s (%s)", container.Name, container.Image)
+}
+
+// describeContainerProject renders "docker / shop" for compose containers
+// and "docker" for the others
+func describeContainerProject(container *scanner.Container) string {
+	if container.ComposeProject == "" {
+		return "docker"
+	}
+	return "docker / " + container.ComposeProject
+}
+
+// imageName strips the registry, namespace and tag: "bitnami/redis:7" is
+// "redis"
+func imageName(image string) string {
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, "@")
+	name, _, _ = strings.Cut(name, ":")
+	return name
+}
@@ -7,10 +7,12 @@ import (
 	"strings"
 )
 
-type DetailedFormatter struct{}
+type DetailedFormatter struct {
+	bindAddress string // --bind, where suggested ports must be free
+}
 
-func NewDetailedFormatter() *DetailedFormatter {
-	return &DetailedFormatter{}
+func NewDetailedFormatter(bindAddress string) *DetailedFormatter {
+	return &DetailedFormatter{bindAddress: bindAddress}
 }
 
 func (df *DetailedFormatter) DetailedTable(statuses []*scanner.PortStatus, projectName string) string {
@@ -26,7 +28,7 @@ func (df *DetailedFormatter) DetailedTable(statuses []*scanner.PortStatus, proje
 
 	// Table Rows
 	for _, status := range statuses {
-		service := df.guessService(status.Port)
+		service := df.formatService(status)
 		statusText := df.formatStatus(status)
 		process := df.formatProcess(status)
 		pid := df.formatPID(status)
@@ -34,7 +36,13 @@ func (df *DetailedFormatter) DetailedTable(statuses []*scanner.PortStatus, proje
 		memory := df.formatMemory(status)
 		uptime := df.formatUptime(status)
 
-		sb.WriteString(df.formatRow(service, strconv.Itoa(status.Port), statusText, process, pid, user, memory, uptime))
+		sb.WriteString(df.formatRow(service, formatPort(status.Spec()), statusText, process, pid, user, memory, uptime))
+	}
+
+	// Where --auto-detect found the ports
+	if detections := describeDetections(statuses); detections != "" {
+		sb.WriteString("\n")
+		sb.WriteString(detections)
 	}
 
 	// Impact Analysis Section
@@ -55,23 +63,23 @@ func (df *DetailedFormatter) DetailedTable(statuses []*scanner.PortStatus, proje
 }
 
 func (df *DetailedFormatter) formatRow(service, port, status, process, pid, user, memory, uptime string) string {
-	return fmt.Sprintf("%-12s %-6s %-10s %-16s %-6s %-12s %-8s %s\n",
+	return fmt.Sprintf("%-12s %-9s %-10s %-16s %-6s %-12s %-8s %s\n",
 		service, port, status, process, pid, user, memory, uptime)
 }
 
 func (df *DetailedFormatter) formatStatus(status *scanner.PortStatus) string {
-	if status.IsAvailable {
-		return "✅ READY"
-	}
-	return "🔴 CONFLICT"
+	return reasonLabel(status)
 }
 
 func (df *DetailedFormatter) formatProcess(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
+	if status.Container != nil {
+		return status.Container.Name
+	}
 	if status.ProcessName != "" {
-		return status.ProcessName
+		return status.ProcessName + extraOwners(status)
 	}
 	return "unknown"
 }
@@ -84,7 +92,7 @@ func (df *DetailedFormatter) formatPID(status *scanner.PortStatus) string {
 }
 
 func (df *DetailedFormatter) formatUser(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
 	if status.User != "" {
@@ -94,7 +102,7 @@ func (df *DetailedFormatter) formatUser(status *scanner.PortStatus) string {
 }
 
 func (df *DetailedFormatter) formatMemory(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
 	if status.MemoryUsage != "" {
@@ -104,7 +112,7 @@ func (df *DetailedFormatter) formatMemory(status *scanner.PortStatus) string {
 }
 
 func (df *DetailedFormatter) formatUptime(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
 	if status.StartTime != "" {
@@ -114,6 +122,22 @@ func (df *DetailedFormatter) formatUptime(status *scanner.PortStatus) string {
 	return "unknown"
 }
 
+func (df *DetailedFormatter) formatService(status *scanner.PortStatus) string {
+	if status.Service != "" { // declared in the project manifest
+		return status.Service
+	}
+	if status.Container != nil { // the analysis would describe docker-proxy
+		if status.Container.ComposeService != "" {
+			return status.Container.ComposeService
+		}
+		return df.guessService(status.Port)
+	}
+	if service := serviceFromAnalysis(status); service != "" {
+		return service
+	}
+	return df.guessService(status.Port)
+}
+
 func (df *DetailedFormatter) guessService(port int) string {
 	// Same service mapping as brief formatter
 	serviceMap := map[int]string{
@@ -157,15 +181,52 @@ func (df *DetailedFormatter) generateImpactAnalysis(statuses []*scanner.PortStat
 		sb.WriteString("• All ports are available and ready for use! ✅\n")
 		sb.WriteString("• No conflicts detected - development environment is clear 🎉\n")
 	} else {
+		// Resolved once per report; on failure the localhost check is skipped
+		localhost, _ := scanner.ResolveLocalhost()
+
 		for _, status := range statuses {
-			if !status.IsAvailable {
-				impact := df.assessImpact(status)
-				sb.WriteString(fmt.Sprintf("• \033[31m%s (%d): %s\033[0m\n", df.guessService(status.Port), status.Port, impact))
-				sb.WriteString(fmt.Sprintf("  - Process: %s (PID %d)\n", status.ProcessName, status.PID))
+			if bindFailed(status) {
+				// Nothing owns the port, so there is no process to assess
+				reason, remedy := ExplainReason(status)
+				sb.WriteString(fmt.Sprintf("• \033[33m%s: %s\033[0m\n", formatPort(status.Spec()), reason))
+				if status.BindError != "" {
+					sb.WriteString(fmt.Sprintf("  - Error: %s\n", status.BindError))
+				}
+				sb.WriteString(fmt.Sprintf("  - Remedy: %s\n", remedy))
+				sb.WriteString("\n")
+			} else if !status.IsAvailable {
+				impact := df.AssessImpact(status)
+				sb.WriteString(fmt.Sprintf("• \033[31m%s (%s): %s\033[0m\n", df.formatService(status), formatPort(status.Spec()), impact))
+				if len(status.Owners) > 1 {
+					sb.WriteString("  - Processes:\n")
+					for _, line := range groupOwners(status.Owners) {
+						sb.WriteString(fmt.Sprintf("    • %s\n", line))
+					}
+				} else {
+					sb.WriteString(fmt.Sprintf("  - Process: %s (PID %d)\n", status.ProcessName, status.PID))
+				}
 				sb.WriteString(fmt.Sprintf("  - User: %s, Memory: %s\n", status.User, status.MemoryUsage))
 				sb.WriteString(fmt.Sprintf("  - Started: %s\n", status.StartTime))
-
-				risk := df.assessRisk(status)
+				sb.WriteString(fmt.Sprintf("  - Bound: %s\n", describeAddresses(status)))
+				if mismatch := scanner.CheckLocalhost(status, localhost); mismatch != nil {
+					sb.WriteString(fmt.Sprintf("  - \033[33m⚠️  localhost: %s\033[0m\n", mismatch.Warning))
+					sb.WriteString(fmt.Sprintf("    Fix: %s\n", mismatch.Fix))
+				}
+				if container := status.Container; container != nil {
+					sb.WriteString(fmt.Sprintf("  - Container: %s, ID %s, port %d inside\n", describeContainer(container), container.ID, container.TargetPort))
+					if container.ComposeProject != "" {
+						sb.WriteString(fmt.Sprintf("  - Compose: project %s, service %s\n", container.ComposeProject, container.ComposeService))
+					}
+				} else if status.Analysis != nil {
+					if isolation := describeIsolation(status.Analysis); isolation != "" {
+						sb.WriteString(fmt.Sprintf("  - Container: %s\n", isolation))
+					}
+					sb.WriteString(fmt.Sprintf("  - Project: %s\n", describeAnalysis(status.Analysis)))
+					sb.WriteString(fmt.Sprintf("  - Framework: %s\n", describeFramework(status.Analysis)))
+					sb.WriteString(fmt.Sprintf("  - Config: %s\n", describeConfigFiles(status.Analysis)))
+				}
+
+				risk := df.AssessRisk(status)
 				sb.WriteString(fmt.Sprintf("  - \033[33mRisk: %s\033[0m\n", risk))
 				sb.WriteString("\n")
 			}
@@ -175,7 +236,8 @@ func (df *DetailedFormatter) generateImpactAnalysis(statuses []*scanner.PortStat
 	return sb.String()
 }
 
-func (df *DetailedFormatter) assessImpact(status *scanner.PortStatus) string {
+// AssessImpact rates how much depends on the service holding the port
+func (df *DetailedFormatter) AssessImpact(status *scanner.PortStatus) string {
 	switch status.Port {
 	case 5432, 3306, 27017:
 		return "HIGH - Database service"
@@ -188,9 +250,18 @@ func (df *DetailedFormatter) assessImpact(status *scanner.PortStatus) string {
 	}
 }
 
-func (df *DetailedFormatter) assessRisk(status *scanner.PortStatus) string {
-	switch status.ProcessName {
-	case "postgres", "mysql", "mongod":
+// AssessRisk describes what is lost if the owning process is terminated
+func (df *DetailedFormatter) AssessRisk(status *scanner.PortStatus) string {
+	kind := status.ProcessName
+	if status.Analysis != nil && status.Analysis.Technology != "unknown" {
+		kind = status.Analysis.Technology
+	}
+	if status.Container != nil { // docker-proxy only forwards to the container
+		kind = imageName(status.Container.Image)
+	}
+
+	switch kind {
+	case "postgres", "mysql", "mongod", "mongodb":
 		return "Data loss if terminated"
 	case "redis":
 		return "Session data loss"
@@ -209,10 +280,10 @@ func (df *DetailedFormatter) generateDetailedResolutions(statuses []*scanner.Por
 	sb.WriteString("\n\033[32m1. PORT MAPPING (RECOMMENDED)\033[0m\n")
 
 	// Show specific port mapping suggestions
+	alternatives := suggestAlternatives(statuses, df.bindAddress)
 	for _, status := range statuses {
 		if !status.IsAvailable {
-			alternative := df.findAlternativePort(status.Port)
-			sb.WriteString(fmt.Sprintf("   %d → %d (available)\n", status.Port, alternative))
+			sb.WriteString(fmt.Sprintf("   %s\n", formatMapping(status.Spec(), alternatives[status.Spec()])))
 		}
 	}
 	sb.WriteString("   Impact: Zero downtime, update configuration files\n")
@@ -223,8 +294,17 @@ func (df *DetailedFormatter) generateDetailedResolutions(statuses []*scanner.Por
 
 	sb.WriteString("\n\033[31m3. PROCESS TERMINATION (HIGH RISK)\033[0m\n")
 	for _, status := range statuses {
-		if !status.IsAvailable {
-			sb.WriteString(fmt.Sprintf("   Stop: %s (PID %d) - %s\n", status.ProcessName, status.PID, df.assessRisk(status)))
+		switch {
+		case status.Container != nil:
+			sb.WriteString(fmt.Sprintf("   Stop: container %s (docker stop %s) - %s\n", status.Container.Name, status.Container.Name, df.AssessRisk(status)))
+		case status.IsAvailable:
+		case status.PID == 0:
+			// No process to stop (TIME_WAIT, a privileged port, an unknown owner)
+			if _, remedy := ExplainReason(status); remedy != "" {
+				sb.WriteString(fmt.Sprintf("   %s: %s\n", formatPort(status.Spec()), remedy))
+			}
+		default:
+			sb.WriteString(fmt.Sprintf("   Stop: %s (PID %d) - %s\n", status.ProcessName, status.PID, df.AssessRisk(status)))
 		}
 	}
 	sb.WriteString("   Impact: Service disruption, potential data loss\n")
@@ -234,24 +314,6 @@ func (df *DetailedFormatter) generateDetailedResolutions(statuses []*scanner.Por
 	return sb.String()
 }
 
-func (df *DetailedFormatter) findAlternativePort(original int) int {
-	// Simple alternative port finder
-	switch original {
-	case 3000:
-		return 3001
-	case 5432:
-		return 5433
-	case 6379:
-		return 6380
-	case 8501:
-		return 8502
-	case 8080:
-		return 8081
-	default:
-		return original + 1
-	}
-}
-
 func (df *DetailedFormatter) generateProcessDetails(statuses []*scanner.PortStatus) string {
 	var sb strings.Builder
 	hasConflicts := false
@@ -264,6 +326,15 @@ func (df *DetailedFormatter) generateProcessDetails(statuses []*scanner.PortStat
 			}
 			sb.WriteString(fmt.Sprintf("• %s (PID %d):\n", status.ProcessName, status.PID))
 			sb.WriteString(fmt.Sprintf("  Command: %s\n", status.CommandLine))
+			if status.Container != nil {
+				sb.WriteString(fmt.Sprintf("  Container: %s\n", describeContainer(status.Container)))
+			}
+			// Workers usually share the owner's command line; parents don't
+			for _, owner := range status.Owners {
+				if owner.Relationship == scanner.RelationParent {
+					sb.WriteString(fmt.Sprintf("  Parent: %s (PID %d): %s\n", owner.Name, owner.PID, owner.CommandLine))
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,23 @@
+package formatter
+
+import (
+	"fmt"
+	"portscanner/scanner"
+	"strings"
+)
+
+// describeDetections cites the project files --auto-detect found each port
+// in, e.g. "• 3000: package.json:7, .env:1"
+func describeDetections(statuses []*scanner.PortStatus) string {
+	var sb strings.Builder
+	for _, status := range statuses {
+		if len(status.DetectedIn) == 0 {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("DETECTED IN:\n")
+		}
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", formatPort(status.Spec()), strings.Join(status.DetectedIn, ", ")))
+	}
+	return sb.String()
+}
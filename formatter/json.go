@@ -0,0 +1,413 @@
+package formatter
+
+import (
+	"encoding/json"
+	"portscanner/compose"
+	"portscanner/scanner"
+	"time"
+)
+
+// JSONSchemaVersion is bumped whenever a field is removed, renamed or changes
+// type. Adding a field does not bump it.
+const JSONSchemaVersion = 1
+
+// Port status values in JSON output
+const (
+	jsonStatusFree        = "free"
+	jsonStatusOccupied    = "occupied"    // a socket holds the port
+	jsonStatusUnavailable = "unavailable" // nothing holds it, but it can't be bound: see reason
+	jsonStatusError       = "error"       // the port couldn't be checked: see error
+)
+
+// ScanMetadata describes the scan run itself
+type ScanMetadata struct {
+	Version     string // port-scanner version
+	Project     string
+	Hostname    string
+	OS          string
+	BindAddress string // --bind, empty when every address was checked
+	Manifest    string // path of the project manifest, empty when there is none
+	Backend     string // socket backend that produced the data, e.g. "netlink"
+	StartedAt   time.Time
+	Duration    time.Duration
+}
+
+type JSONFormatter struct{}
+
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{}
+}
+
+// jsonReport is the top-level object. See "JSON Output" in the README for
+// the documented schema.
+type jsonReport struct {
+	SchemaVersion int          `json:"schema_version"`
+	Scan          jsonScan     `json:"scan"`
+	Ports         []jsonResult `json:"ports"`
+}
+
+// jsonProcessReport is the top-level object of the pid and who commands
+type jsonProcessReport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Scan          jsonScan            `json:"scan"`
+	Processes     []jsonProcessResult `json:"processes"`
+}
+
+// jsonComposeReport is the top-level object of the compose command
+type jsonComposeReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Scan          jsonScan             `json:"scan"`
+	Files         []string             `json:"files"`
+	Services      []jsonComposeService `json:"services"`
+}
+
+type jsonComposeService struct {
+	Name  string            `json:"name"`
+	Ports []jsonComposePort `json:"ports"`
+}
+
+// jsonComposePort is a ports[] result of the host port plus where the
+// compose files publish it
+type jsonComposePort struct {
+	HostIP      string      `json:"host_ip"`   // "" when published on every interface
+	RangeEnd    int         `json:"range_end"` // last port of a host range docker picks any free port from, else 0
+	Target      string      `json:"target"`
+	Location    string      `json:"location"`     // "docker-compose.yml:12"
+	ClashesWith []jsonClash `json:"clashes_with"` // other entries publishing the same host port
+	jsonResult
+}
+
+type jsonClash struct {
+	Service  string `json:"service"`
+	Location string `json:"location"`
+}
+
+type jsonScan struct {
+	Tool        string `json:"tool"`
+	Version     string `json:"version"`
+	Project     string `json:"project"`
+	Hostname    string `json:"hostname"`
+	OS          string `json:"os"`
+	BindAddress string `json:"bind_address"` // "" when every address was checked
+	Manifest    string `json:"manifest"`     // "" when there is no project manifest
+	Backend     string `json:"backend"`
+	StartedAt   string `json:"started_at"` // RFC3339
+	DurationMS  int64  `json:"duration_ms"`
+	PortCount   int    `json:"port_count"`
+}
+
+type jsonResult struct {
+	Port       int            `json:"port"`
+	Protocol   string         `json:"protocol"` // "tcp" or "udp"
+	Service    string         `json:"service"`  // name from the project manifest, "" when not declared
+	Optional   bool           `json:"optional"`
+	DetectedIn []string       `json:"detected_in"` // "file:line" for each --auto-detect finding
+	Status     string         `json:"status"`      // "free", "occupied", "unavailable" or "error"
+	Addresses  []string       `json:"addresses"`
+	Family     string         `json:"family"` // "ipv4", "ipv6", "dual" or "" when nothing is bound
+	Reason     string         `json:"reason"` // why the port can't be bound, "" when free
+	BindError  string         `json:"bind_error"`
+	Owner      *jsonOwner     `json:"owner"`
+	Owners     []jsonProcess  `json:"owners"`    // every process involved, owner first
+	Container  *jsonContainer `json:"container"` // Docker container publishing the port, null when none
+	Error      *jsonError     `json:"error"`
+	Analysis   *jsonAnalysis  `json:"analysis"`
+}
+
+type jsonContainer struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Image          string `json:"image"`
+	ComposeProject string `json:"compose_project"` // "" when not started by docker compose
+	ComposeService string `json:"compose_service"`
+	HostIP         string `json:"host_ip"` // "" when published on every interface
+	TargetPort     int    `json:"target_port"`
+}
+
+type jsonOwner struct {
+	PID         int     `json:"pid"`
+	Name        string  `json:"name"`
+	User        string  `json:"user"`
+	CommandLine string  `json:"command_line"`
+	MemoryBytes *int64  `json:"memory_bytes"`
+	StartTime   *string `json:"start_time"` // RFC3339
+}
+
+type jsonProcess struct {
+	PID          int    `json:"pid"`
+	PPID         int    `json:"ppid"`
+	Name         string `json:"name"`
+	User         string `json:"user"`
+	CommandLine  string `json:"command_line"`
+	Relationship string `json:"relationship"` // "listener", "worker" or "parent"
+}
+
+type jsonProcessResult struct {
+	PID         int           `json:"pid"`
+	Name        string        `json:"name"`
+	User        string        `json:"user"`
+	CommandLine string        `json:"command_line"`
+	Listening   []jsonSocket  `json:"listening"`
+	Connections []jsonSocket  `json:"connections"`
+	Analysis    *jsonAnalysis `json:"analysis"`
+}
+
+type jsonSocket struct {
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"local_address"`
+	LocalPort     int    `json:"local_port"`
+	RemoteAddress string `json:"remote_address"` // "" when not connected
+	RemotePort    int    `json:"remote_port"`
+}
+
+type jsonError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonAnalysis struct {
+	Technology    string   `json:"technology"`
+	Framework     string   `json:"framework"`
+	ServiceType   string   `json:"service_type"`
+	WorkingDir    string   `json:"working_dir"`
+	ProjectPath   string   `json:"project_path"`
+	ConfigFiles   []string `json:"config_files"`
+	Args          []string `json:"args"`
+	DetectedPorts []int    `json:"detected_ports"`
+
+	ContainerID            string `json:"container_id"`      // from the cgroup, "" outside a container
+	ContainerRuntime       string `json:"container_runtime"` // "docker", "containerd", "podman", "cri-o", "kubernetes"
+	SeparatePIDNamespace   bool   `json:"separate_pid_namespace"`
+	SeparateMountNamespace bool   `json:"separate_mount_namespace"`
+	RootDir                string `json:"root_dir"` // "/proc/<pid>/root" with a separate mount namespace
+}
+
+func (jf *JSONFormatter) Report(statuses []*scanner.PortStatus, meta ScanMetadata) (string, error) {
+	report := jsonReport{
+		SchemaVersion: JSONSchemaVersion,
+		Scan:          jf.formatScan(meta, len(statuses)),
+		Ports:         make([]jsonResult, 0, len(statuses)),
+	}
+
+	for _, status := range statuses {
+		report.Ports = append(report.Ports, jf.formatResult(status))
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ProcessReport encodes the sockets held by each analyzed process.
+// scan.port_count is the number of sockets reported.
+func (jf *JSONFormatter) ProcessReport(analyses []*scanner.ProcessAnalysis, meta ScanMetadata) (string, error) {
+	report := jsonProcessReport{
+		SchemaVersion: JSONSchemaVersion,
+		Scan:          jf.formatScan(meta, 0),
+		Processes:     make([]jsonProcessResult, 0, len(analyses)),
+	}
+
+	for _, analysis := range analyses {
+		// Empty lists are encoded as [] rather than null
+		result := jsonProcessResult{
+			PID:         analysis.PID,
+			Name:        analysis.Name,
+			User:        analysis.User,
+			CommandLine: analysis.CommandLine,
+			Listening:   []jsonSocket{},
+			Connections: []jsonSocket{},
+			Analysis:    jf.formatAnalysis(analysis),
+		}
+		for _, socket := range analysis.Sockets {
+			encoded := jsonSocket{
+				Protocol:      socket.Protocol,
+				LocalAddress:  socket.LocalAddress,
+				LocalPort:     socket.LocalPort,
+				RemoteAddress: socket.RemoteAddress,
+				RemotePort:    socket.RemotePort,
+			}
+			if socket.Listening {
+				result.Listening = append(result.Listening, encoded)
+			} else {
+				result.Connections = append(result.Connections, encoded)
+			}
+		}
+		report.Scan.PortCount += len(analysis.Sockets)
+		report.Processes = append(report.Processes, result)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ComposeReport encodes every published port by compose service.
+// scan.port_count is the number of distinct host ports checked.
+func (jf *JSONFormatter) ComposeReport(project *compose.Project, statuses map[compose.Binding]*scanner.PortStatus, meta ScanMetadata) (string, error) {
+	report := jsonComposeReport{
+		SchemaVersion: JSONSchemaVersion,
+		Scan:          jf.formatScan(meta, len(statuses)),
+		Files:         append([]string{}, project.Files...),
+		Services:      make([]jsonComposeService, 0, len(project.Services)),
+	}
+
+	for _, service := range project.Services {
+		encoded := jsonComposeService{Name: service.Name, Ports: []jsonComposePort{}}
+		for _, port := range service.Ports {
+			status := statuses[port.Binding()]
+			if status == nil {
+				continue
+			}
+			result := jsonComposePort{
+				HostIP:      port.HostIP,
+				RangeEnd:    port.RangeEnd,
+				Target:      port.Target,
+				Location:    port.Location(),
+				ClashesWith: []jsonClash{},
+				jsonResult:  jf.formatResult(status),
+			}
+			for _, clash := range project.ClashesWith(port) {
+				result.ClashesWith = append(result.ClashesWith, jsonClash{Service: clash.Service, Location: clash.Port.Location()})
+			}
+			encoded.Ports = append(encoded.Ports, result)
+		}
+		report.Services = append(report.Services, encoded)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (jf *JSONFormatter) formatScan(meta ScanMetadata, portCount int) jsonScan {
+	return jsonScan{
+		Tool:        "port-scanner",
+		Version:     meta.Version,
+		Project:     meta.Project,
+		Hostname:    meta.Hostname,
+		OS:          meta.OS,
+		BindAddress: meta.BindAddress,
+		Manifest:    meta.Manifest,
+		Backend:     meta.Backend,
+		StartedAt:   meta.StartedAt.Format(time.RFC3339),
+		DurationMS:  meta.Duration.Milliseconds(),
+		PortCount:   portCount,
+	}
+}
+
+func (jf *JSONFormatter) formatResult(status *scanner.PortStatus) jsonResult {
+	result := jsonResult{
+		Port:       status.Port,
+		Protocol:   status.Protocol,
+		Service:    status.Service,
+		Optional:   status.Optional,
+		DetectedIn: append([]string{}, status.DetectedIn...),
+		Status:     jf.formatStatus(status),
+		// Empty lists are encoded as [] rather than null
+		Addresses: append([]string{}, status.Addresses...),
+		Family:    status.Family,
+		Reason:    status.Reason,
+		BindError: status.BindError,
+		Owners:    make([]jsonProcess, 0, len(status.Owners)),
+	}
+	if status.Error != "" {
+		result.Error = &jsonError{Code: status.ErrorCode, Message: status.Error}
+	}
+
+	if !status.IsAvailable && status.PID != 0 {
+		result.Owner = jf.formatOwner(status)
+	}
+	for _, owner := range status.Owners {
+		result.Owners = append(result.Owners, jsonProcess{
+			PID:          owner.PID,
+			PPID:         owner.PPID,
+			Name:         owner.Name,
+			User:         owner.User,
+			CommandLine:  owner.CommandLine,
+			Relationship: owner.Relationship,
+		})
+	}
+
+	if container := status.Container; container != nil {
+		result.Container = &jsonContainer{
+			ID:             container.ID,
+			Name:           container.Name,
+			Image:          container.Image,
+			ComposeProject: container.ComposeProject,
+			ComposeService: container.ComposeService,
+			HostIP:         container.HostIP,
+			TargetPort:     container.TargetPort,
+		}
+	}
+
+	if status.Analysis != nil {
+		result.Analysis = jf.formatAnalysis(status.Analysis)
+	}
+
+	return result
+}
+
+// formatStatus tells a port someone holds from one we couldn't bind for
+// another reason, and from one we couldn't check at all
+func (jf *JSONFormatter) formatStatus(status *scanner.PortStatus) string {
+	switch {
+	case status.IsAvailable:
+		return jsonStatusFree
+	case status.ErrorCode == scanner.ErrCodeScanFailed:
+		return jsonStatusError
+	case status.Reason == scanner.ReasonInUse:
+		return jsonStatusOccupied
+	case status.Reason == scanner.ReasonTimeWait, status.Reason == scanner.ReasonPermissionDenied,
+		status.Reason == scanner.ReasonAddressNotAvailable:
+		return jsonStatusUnavailable
+	}
+	return jsonStatusError
+}
+
+func (jf *JSONFormatter) formatOwner(status *scanner.PortStatus) *jsonOwner {
+	owner := &jsonOwner{
+		PID:         status.PID,
+		Name:        status.ProcessName,
+		User:        status.User,
+		CommandLine: status.CommandLine,
+	}
+	if status.MemoryBytes > 0 {
+		memory := status.MemoryBytes
+		owner.MemoryBytes = &memory
+	}
+	if !status.Started.IsZero() {
+		started := status.Started.Format(time.RFC3339)
+		owner.StartTime = &started
+	}
+	return owner
+}
+
+func (jf *JSONFormatter) formatAnalysis(analysis *scanner.ProcessAnalysis) *jsonAnalysis {
+	// Empty lists are encoded as [] rather than null
+	result := &jsonAnalysis{
+		Technology:    analysis.Technology,
+		Framework:     analysis.Framework,
+		ServiceType:   analysis.ServiceType,
+		WorkingDir:    analysis.WorkingDir,
+		ProjectPath:   analysis.ProjectPath,
+		ConfigFiles:   []string{},
+		Args:          []string{},
+		DetectedPorts: []int{},
+
+		ContainerID:            analysis.ContainerID,
+		ContainerRuntime:       analysis.ContainerRuntime,
+		SeparatePIDNamespace:   analysis.SeparatePIDNamespace,
+		SeparateMountNamespace: analysis.SeparateMountNamespace,
+		RootDir:                analysis.RootDir,
+	}
+	result.ConfigFiles = append(result.ConfigFiles, analysis.ConfigFiles...)
+	result.Args = append(result.Args, analysis.Args...)
+	result.DetectedPorts = append(result.DetectedPorts, analysis.DetectedPorts...)
+	return result
+}
@@ -0,0 +1,176 @@
+package formatter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"portscanner/compose"
+	"portscanner/scanner"
+	"testing"
+	"time"
+)
+
+// Regenerate with: go test ./formatter -run Golden -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenMeta is a fixed scan, so only schema changes show up as diffs
+var goldenMeta = ScanMetadata{
+	Version:   "1.2.3",
+	Project:   "shop",
+	Hostname:  "devbox",
+	OS:        "linux",
+	Backend:   "netlink",
+	StartedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+	Duration:  42 * time.Millisecond,
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got+"\n" != string(want) {
+		t.Errorf("%s differs from the output, re-run with -update if the change is intended\ngot:\n%s", path, got)
+	}
+}
+
+// goldenStatuses covers every JSON status and the optional objects
+func goldenStatuses() []*scanner.PortStatus {
+	started := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	return []*scanner.PortStatus{
+		{
+			Port: 3000, Protocol: scanner.ProtocolTCP, IsAvailable: true,
+			Service: "web", DetectedIn: []string{"package.json:7"},
+		},
+		{
+			Port: 5432, Protocol: scanner.ProtocolTCP,
+			ProcessName: "postgres", PID: 812, User: "postgres",
+			CommandLine: "/usr/lib/postgresql/16/bin/postgres -D /var/lib/postgresql/16/main",
+			MemoryBytes: 31457280, Started: started,
+			Reason: scanner.ReasonInUse, BindError: "bind: address already in use",
+			Addresses: []string{"127.0.0.1", "::1"}, Family: scanner.FamilyDual,
+			Owners: []scanner.Owner{
+				{PID: 812, PPID: 1, Name: "postgres", User: "postgres", CommandLine: "/usr/lib/postgresql/16/bin/postgres -D /var/lib/postgresql/16/main", Relationship: scanner.RelationListener},
+			},
+			Service: "db",
+			Analysis: &scanner.ProcessAnalysis{
+				PID: 812, Name: "postgres", Technology: "postgres", ServiceType: "database",
+				WorkingDir: "/var/lib/postgresql", Args: []string{"/usr/lib/postgresql/16/bin/postgres", "-D", "/var/lib/postgresql/16/main"},
+				DetectedPorts: []int{5432},
+			},
+		},
+		{
+			Port: 8080, Protocol: scanner.ProtocolTCP,
+			ProcessName: "docker-proxy", PID: 2301, User: "root",
+			Reason: scanner.ReasonInUse, BindError: "bind: address already in use",
+			Addresses: []string{"0.0.0.0"}, Family: scanner.FamilyIPv4,
+			Owners: []scanner.Owner{
+				{PID: 2301, PPID: 990, Name: "docker-proxy", User: "root", Relationship: scanner.RelationListener},
+			},
+			Container: &scanner.Container{
+				ID: "4f2a9c1be0d7", Name: "shop-web-1", Image: "nginx:1.25",
+				ComposeProject: "shop", ComposeService: "web", TargetPort: 80,
+			},
+		},
+		{
+			Port: 9000, Protocol: scanner.ProtocolTCP,
+			Reason: scanner.ReasonInUse, BindError: "bind: address already in use",
+			Addresses: []string{"::"}, Family: scanner.FamilyIPv6,
+			Error: "owner not visible without root", ErrorCode: scanner.ErrCodeOwnerLookup,
+		},
+		{
+			Port: 80, Protocol: scanner.ProtocolTCP,
+			Reason: scanner.ReasonPermissionDenied, BindError: "bind: permission denied",
+		},
+		{
+			Port: 8125, Protocol: scanner.ProtocolUDP,
+			Reason: scanner.ReasonBindFailed, BindError: "bind: no buffer space available",
+			Optional: true,
+		},
+		{
+			Port: 6379, Protocol: scanner.ProtocolTCP,
+			Error: "context canceled", ErrorCode: scanner.ErrCodeScanFailed,
+		},
+	}
+}
+
+func TestReportGolden(t *testing.T) {
+	output, err := NewJSONFormatter().Report(goldenStatuses(), goldenMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "report.golden", output)
+}
+
+func TestProcessReportGolden(t *testing.T) {
+	analyses := []*scanner.ProcessAnalysis{
+		{
+			PID: 4120, Name: "node", User: "dev", CommandLine: "node server.js",
+			Technology: "node", Framework: "nextjs", ServiceType: "web",
+			WorkingDir: "/home/dev/shop", ProjectPath: "/home/dev/shop",
+			ConfigFiles: []string{"/home/dev/shop/package.json"},
+			Args:        []string{"node", "server.js"},
+			Sockets: []scanner.ProcessSocket{
+				{Protocol: scanner.ProtocolTCP, LocalAddress: "::", LocalPort: 3000, Listening: true},
+				{Protocol: scanner.ProtocolTCP, LocalAddress: "127.0.0.1", LocalPort: 51712, RemoteAddress: "127.0.0.1", RemotePort: 5432},
+			},
+			DetectedPorts: []int{3000, 5432},
+		},
+		{
+			PID: 6001, Name: "uvicorn", User: "root", CommandLine: "uvicorn app:app",
+			Technology: "python", ServiceType: "web",
+			WorkingDir: "/app", ProjectPath: "/proc/6001/root/app",
+			ContainerID:            "4f2a9c1be0d7e1a3b5c7d9f0a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8",
+			ContainerRuntime:       scanner.RuntimeDocker,
+			SeparatePIDNamespace:   true,
+			SeparateMountNamespace: true,
+			RootDir:                "/proc/6001/root",
+		},
+	}
+
+	output, err := NewJSONFormatter().ProcessReport(analyses, goldenMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "process_report.golden", output)
+}
+
+func TestComposeReportGolden(t *testing.T) {
+	tcp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP} }
+	web := compose.Port{Spec: tcp(8080), Target: "80", File: "compose.yaml", Line: 5}
+	admin := compose.Port{Spec: tcp(8080), Target: "8000", File: "compose.override.yaml", Line: 4}
+	db := compose.Port{HostIP: "127.0.0.1", Spec: tcp(5432), Target: "5432", File: "compose.yaml", Line: 9}
+	project := &compose.Project{
+		Name:  "shop",
+		Dir:   "/home/dev/shop",
+		Files: []string{"/home/dev/shop/compose.yaml", "/home/dev/shop/compose.override.yaml"},
+		Services: []compose.Service{
+			{Name: "web", Ports: []compose.Port{web}},
+			{Name: "db", Ports: []compose.Port{db}},
+			{Name: "admin", Ports: []compose.Port{admin}},
+			{Name: "worker"},
+		},
+	}
+	statuses := map[compose.Binding]*scanner.PortStatus{
+		web.Binding(): {Port: 8080, Protocol: scanner.ProtocolTCP, IsAvailable: true},
+		db.Binding(): {
+			Port: 5432, Protocol: scanner.ProtocolTCP,
+			ProcessName: "postgres", PID: 812, User: "postgres",
+			Reason: scanner.ReasonInUse, BindError: "bind: address already in use",
+			Addresses: []string{"127.0.0.1"}, Family: scanner.FamilyIPv4,
+		},
+	}
+
+	output, err := NewJSONFormatter().ComposeReport(project, statuses, goldenMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "compose_report.golden", output)
+}
@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"fmt"
+	"portscanner/scanner"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ListFormatter struct{}
+
+func NewListFormatter() *ListFormatter {
+	return &ListFormatter{}
+}
+
+// ProjectTable lists listening ports grouped by the project root of their
+// owner. Projects are sorted by path; ports without one come last.
+func (lf *ListFormatter) ProjectTable(statuses []*scanner.PortStatus) string {
+	var sb strings.Builder
+
+	projects := make(map[string][]*scanner.PortStatus)
+	for _, status := range statuses {
+		// Processes of one container share a group, whatever their PIDs
+		path := ""
+		if status.Analysis != nil && status.Analysis.ProjectPath != "" {
+			path = describeProjectPath(status.Analysis)
+		}
+		projects[path] = append(projects[path], status)
+	}
+
+	var paths []string
+	for path := range projects {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	projectCount := len(paths)
+	if _, ok := projects[""]; ok {
+		paths = append(paths, "")
+	}
+
+	// Header
+	sb.WriteString(fmt.Sprintf("LISTENING PORTS: %s, %s\n", plural(len(statuses), "port"), plural(projectCount, "project")))
+	sb.WriteString("──────────────────────────────\n")
+
+	if len(statuses) == 0 {
+		sb.WriteString("\n• Nothing is listening that matches the filters\n")
+		return sb.String()
+	}
+
+	for _, path := range paths {
+		sb.WriteString("\n")
+		if path == "" {
+			sb.WriteString("📦 No project\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("📁 %s\n", path))
+		}
+
+		sb.WriteString(lf.formatRow("PORT", "BOUND", "PROCESS", "USER", "TECH", "UPTIME"))
+		sb.WriteString(lf.formatRow("────", "─────", "───────", "────", "────", "──────"))
+		for _, status := range projects[path] {
+			sb.WriteString(lf.formatRow(formatPort(status.Spec()), describeFamily(status), lf.formatProcess(status),
+				lf.formatUser(status), lf.formatTech(status), describeUptime(status.Started)))
+		}
+	}
+	return sb.String()
+}
+
+func (lf *ListFormatter) formatRow(port, bound, process, user, tech, uptime string) string {
+	return fmt.Sprintf("   %-9s %-10s %-22s %-12s %-18s %s\n", port, bound, process, user, tech, uptime)
+}
+
+func (lf *ListFormatter) formatProcess(status *scanner.PortStatus) string {
+	if status.Container != nil {
+		return status.Container.Name
+	}
+	if status.ProcessName == "" {
+		return "unknown"
+	}
+	if status.PID == 0 {
+		return status.ProcessName
+	}
+	return fmt.Sprintf("%s:%d%s", status.ProcessName, status.PID, extraOwners(status))
+}
+
+func (lf *ListFormatter) formatTech(status *scanner.PortStatus) string {
+	if status.Container != nil {
+		return "docker / " + imageName(status.Container.Image)
+	}
+	return describeTech(status.Analysis)
+}
+
+func (lf *ListFormatter) formatUser(status *scanner.PortStatus) string {
+	if status.User == "" {
+		return "unknown"
+	}
+	return status.User
+}
+
+// describeUptime renders how long ago started was, e.g. "3d4h", "2h15m" or "40s"
+func describeUptime(started time.Time) string {
+	if started.IsZero() {
+		return "-"
+	}
+
+	uptime := time.Since(started)
+	switch {
+	case uptime >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(uptime.Hours())/24, int(uptime.Hours())%24)
+	case uptime >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(uptime.Hours()), int(uptime.Minutes())%60)
+	case uptime >= time.Minute:
+		return fmt.Sprintf("%dm", int(uptime.Minutes()))
+	}
+	return fmt.Sprintf("%ds", int(uptime.Seconds()))
+}
+
+// plural renders "1 port" or "3 ports"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"fmt"
+	"portscanner/scanner"
+	"strconv"
+	"strings"
+)
+
+// extraOwners renders " +3" for the processes beyond the socket's owner
+func extraOwners(status *scanner.PortStatus) string {
+	if len(status.Owners) <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" +%d", len(status.Owners)-1)
+}
+
+// groupOwners renders one line per relationship and process name, e.g.
+// "worker: gunicorn (PIDs 101, 102, 103)". Owners are already ordered
+// listeners, workers, parent.
+func groupOwners(owners []scanner.Owner) []string {
+	type group struct {
+		relationship string
+		name         string
+		pids         []string
+	}
+
+	var groups []*group
+	for _, owner := range owners {
+		var current *group
+		for _, g := range groups {
+			if g.relationship == owner.Relationship && g.name == owner.Name {
+				current = g
+				break
+			}
+		}
+		if current == nil {
+			current = &group{relationship: owner.Relationship, name: owner.Name}
+			groups = append(groups, current)
+		}
+		current.pids = append(current.pids, strconv.Itoa(owner.PID))
+	}
+
+	lines := make([]string, len(groups))
+	for i, g := range groups {
+		label := "PID"
+		if len(g.pids) > 1 {
+			label = "PIDs"
+		}
+		lines[i] = fmt.Sprintf("%s: %s (%s %s)", g.relationship, g.name, label, strings.Join(g.pids, ", "))
+	}
+	return lines
+}
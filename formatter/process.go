@@ -0,0 +1,81 @@
+package formatter
+
+import (
+	"fmt"
+	"net"
+	"portscanner/scanner"
+	"strconv"
+	"strings"
+)
+
+type ProcessFormatter struct{}
+
+func NewProcessFormatter() *ProcessFormatter {
+	return &ProcessFormatter{}
+}
+
+// ProcessTable describes each process and the sockets it holds, listeners
+// first
+func (pf *ProcessFormatter) ProcessTable(analyses []*scanner.ProcessAnalysis, target string) string {
+	var sb strings.Builder
+
+	// Header
+	sb.WriteString(fmt.Sprintf("PORTS HELD BY: %s\n", target))
+	sb.WriteString("──────────────────────────────\n")
+
+	for _, analysis := range analyses {
+		sb.WriteString(fmt.Sprintf("\n⚙️  %s (PID %d)\n", analysis.Name, analysis.PID))
+		sb.WriteString(fmt.Sprintf("   User:     %s\n", analysis.User))
+		sb.WriteString(fmt.Sprintf("   Command:  %s\n", analysis.CommandLine))
+		sb.WriteString(fmt.Sprintf("   Tech:     %s (%s)\n", describeTech(analysis), analysis.ServiceType))
+		project := "-"
+		if analysis.ProjectPath != "" {
+			project = describeProjectPath(analysis)
+		}
+		sb.WriteString(fmt.Sprintf("   Project:  %s\n", project))
+		if isolation := describeIsolation(analysis); isolation != "" {
+			sb.WriteString(fmt.Sprintf("   Container: %s\n", isolation))
+		}
+		sb.WriteString(fmt.Sprintf("   Config:   %s\n", describeConfigFiles(analysis)))
+
+		if len(analysis.Sockets) == 0 {
+			// Also the case for other users' processes when not run as root
+			sb.WriteString("   No sockets found\n")
+			continue
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(pf.formatRow("PROTO", "STATE", "LOCAL", "REMOTE"))
+		sb.WriteString(pf.formatRow("─────", "─────", "─────", "──────"))
+		for _, socket := range analysis.Sockets {
+			remote := "-"
+			if socket.RemotePort != 0 {
+				remote = hostPort(socket.RemoteAddress, socket.RemotePort)
+			}
+			sb.WriteString(pf.formatRow(socket.Protocol, pf.formatState(socket), hostPort(socket.LocalAddress, socket.LocalPort), remote))
+		}
+	}
+	return sb.String()
+}
+
+func (pf *ProcessFormatter) formatRow(protocol, state, local, remote string) string {
+	return fmt.Sprintf("   %-6s %-10s %-28s %s\n", protocol, state, local, remote)
+}
+
+func (pf *ProcessFormatter) formatState(socket scanner.ProcessSocket) string {
+	switch {
+	case socket.Listening:
+		return "LISTEN"
+	case socket.RemotePort != 0:
+		return "CONNECTED"
+	}
+	return "BOUND"
+}
+
+// hostPort renders "127.0.0.1:3000" or "[::1]:3000"
+func hostPort(address string, port int) string {
+	if address == "" {
+		address = "*"
+	}
+	return net.JoinHostPort(address, strconv.Itoa(port))
+}
@@ -0,0 +1,79 @@
+package formatter
+
+import (
+	"fmt"
+	"portscanner/scanner"
+	"strings"
+)
+
+// ExplainReason says why status's port couldn't be bound and what to do
+// about it. Both are empty when the port is available.
+func ExplainReason(status *scanner.PortStatus) (string, string) {
+	switch status.Reason {
+	case "":
+		return "", ""
+	case scanner.ReasonInUse:
+		return "Another socket is listening on the port",
+			"Use the suggested alternative port, or stop the owning process"
+	case scanner.ReasonTimeWait:
+		return "Only closed connections linger in TIME_WAIT; no process owns the port",
+			"Wait up to a minute for the kernel to release it, or set SO_REUSEADDR on the server socket"
+	case scanner.ReasonPermissionDenied:
+		if status.Port <= 1023 {
+			return "Ports below 1024 need elevated privileges",
+				"Run with sudo, grant CAP_NET_BIND_SERVICE (setcap 'cap_net_bind_service=+ep' <binary>), or use a port above 1023"
+		}
+		return "The OS refused the bind",
+			"Check firewall, SELinux/AppArmor or sandbox rules for this port"
+	case scanner.ReasonAddressNotAvailable:
+		return "The bind address isn't configured on this host",
+			"Pick an address shown by `ip addr` (or `ifconfig`), or drop --bind"
+	}
+	return fmt.Sprintf("Bind failed: %s", status.BindError),
+		"Re-run the scan; if it keeps failing, check the error above"
+}
+
+// reasonLabel is the STATUS column text
+func reasonLabel(status *scanner.PortStatus) string {
+	switch {
+	case status.IsAvailable:
+		return "✅ READY"
+	case status.Reason == scanner.ReasonTimeWait:
+		return "⏳ TIME_WAIT"
+	case status.Reason == scanner.ReasonPermissionDenied:
+		return "🔒 DENIED"
+	case status.Reason == scanner.ReasonAddressNotAvailable:
+		return "❓ NO ADDR"
+	case status.Reason == scanner.ReasonBindFailed:
+		return "⚠️  ERROR"
+	}
+	// If port is not available, it's a conflict (even if we have error details)
+	return "🔴 CONFLICT"
+}
+
+// bindFailed reports whether status's port is unavailable without a
+// process holding it, e.g. a privileged port
+func bindFailed(status *scanner.PortStatus) bool {
+	return status.Reason != "" && status.Reason != scanner.ReasonInUse
+}
+
+// describeBindFailures explains every port that is unavailable for another
+// reason than a listener holding it
+func describeBindFailures(statuses []*scanner.PortStatus) string {
+	var sb strings.Builder
+	for _, status := range statuses {
+		if !bindFailed(status) {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("BIND FAILURES:\n")
+		}
+		reason, remedy := ExplainReason(status)
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", formatPort(status.Spec()), reason))
+		sb.WriteString(fmt.Sprintf("  Remedy: %s\n", remedy))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
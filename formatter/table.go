@@ -3,14 +3,15 @@ package formatter
 import (
 	"fmt"
 	"portscanner/scanner"
-	"strconv"
 	"strings"
 )
 
-type TableFormatter struct{}
+type TableFormatter struct {
+	bindAddress string // --bind, where suggested ports must be free
+}
 
-func NewTableFormatter() *TableFormatter {
-	return &TableFormatter{}
+func NewTableFormatter(bindAddress string) *TableFormatter {
+	return &TableFormatter{bindAddress: bindAddress}
 }
 
 func (tf *TableFormatter) BriefTable(statuses []*scanner.PortStatus, projectName string) string {
@@ -21,25 +22,38 @@ func (tf *TableFormatter) BriefTable(statuses []*scanner.PortStatus, projectName
 	sb.WriteString("──────────────────────────────\n\n")
 
 	// Table Header
-	sb.WriteString(tf.formatRow("SERVICE", "PORT", "STATUS", "PROCESS", "IMPACT", "UPTIME", "RESOURCES"))
-	sb.WriteString(tf.formatRow("───────", "────", "──────", "───────", "──────", "──────", "─────────"))
+	sb.WriteString(tf.formatRow("SERVICE", "PORT", "STATUS", "BOUND", "PROCESS", "IMPACT", "UPTIME", "FRAMEWORK", "PROJECT"))
+	sb.WriteString(tf.formatRow("───────", "────", "──────", "─────", "───────", "──────", "──────", "─────────", "───────"))
 
 	// Table Rows
 	for _, status := range statuses {
-		service := tf.guessService(status.Port)
+		service := tf.formatService(status)
 		statusText := tf.formatStatus(status)
+		bound := describeFamily(status)
 		process := tf.formatProcess(status)
 		impact := tf.assessImpact(status)
 		uptime := "-"
-		resources := tf.assessResources(status)
+		framework := describeFramework(status.Analysis)
+		project := describeAnalysis(status.Analysis)
+		if status.Container != nil { // the analysis would describe docker-proxy
+			framework = imageName(status.Container.Image)
+			project = describeContainerProject(status.Container)
+		}
 
-		sb.WriteString(tf.formatRow(service, strconv.Itoa(status.Port), statusText, process, impact, uptime, resources))
+		sb.WriteString(tf.formatRow(service, formatPort(status.Spec()), statusText, bound, process, impact, uptime, framework, project))
+	}
+
+	// Where --auto-detect found the ports
+	if detections := describeDetections(statuses); detections != "" {
+		sb.WriteString("\n")
+		sb.WriteString(detections)
 	}
 
 	// Resolution section - ALWAYS show if we have any non-available ports
 	conflicts := tf.countConflicts(statuses)
 	if conflicts > 0 {
 		sb.WriteString("\n")
+		sb.WriteString(describeBindFailures(statuses))
 		sb.WriteString(tf.generateResolutions(statuses))
 	}
 
@@ -51,25 +65,40 @@ func (tf *TableFormatter) BriefTable(statuses []*scanner.PortStatus, projectName
 	return sb.String()
 }
 
-func (tf *TableFormatter) formatRow(service, port, status, process, impact, uptime, resources string) string {
-	return fmt.Sprintf("%-12s %-6s %-10s %-16s %-8s %-8s %s\n",
-		service, port, status, process, impact, uptime, resources)
+func (tf *TableFormatter) formatRow(service, port, status, bound, process, impact, uptime, framework, project string) string {
+	return fmt.Sprintf("%-12s %-9s %-10s %-10s %-16s %-8s %-8s %-12s %s\n",
+		service, port, status, bound, process, impact, uptime, framework, project)
 }
 
-func (tf *TableFormatter) formatStatus(status *scanner.PortStatus) string {
-	if status.IsAvailable {
-		return "✅ READY"
+func (tf *TableFormatter) formatService(status *scanner.PortStatus) string {
+	if status.Service != "" { // declared in the project manifest
+		return status.Service
 	}
-	// If port is not available, it's a conflict (even if we have error details)
-	return "🔴 CONFLICT"
+	if status.Container != nil { // the analysis would describe docker-proxy
+		if status.Container.ComposeService != "" {
+			return status.Container.ComposeService
+		}
+		return tf.guessService(status.Port)
+	}
+	if service := serviceFromAnalysis(status); service != "" {
+		return service
+	}
+	return tf.guessService(status.Port)
+}
+
+func (tf *TableFormatter) formatStatus(status *scanner.PortStatus) string {
+	return reasonLabel(status)
 }
 
 func (tf *TableFormatter) formatProcess(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
+	if status.Container != nil {
+		return status.Container.Name
+	}
 	if status.ProcessName != "" && status.PID != 0 {
-		return fmt.Sprintf("%s:%d", status.ProcessName, status.PID)
+		return fmt.Sprintf("%s:%d%s", status.ProcessName, status.PID, extraOwners(status))
 	}
 	if status.ProcessName != "" {
 		return status.ProcessName
@@ -104,7 +133,7 @@ func (tf *TableFormatter) guessService(port int) string {
 }
 
 func (tf *TableFormatter) assessImpact(status *scanner.PortStatus) string {
-	if status.IsAvailable {
+	if status.IsAvailable || bindFailed(status) {
 		return "-"
 	}
 
@@ -120,34 +149,6 @@ func (tf *TableFormatter) assessImpact(status *scanner.PortStatus) string {
 	}
 }
 
-func (tf *TableFormatter) assessResources(status *scanner.PortStatus) string {
-	if status.IsAvailable {
-		return "Available"
-	}
-
-	// Port-specific detection
-	switch status.Port {
-	case 8501:
-		return "Streamlit App"
-	case 5173:
-		return "Vite Dev Server"
-	case 8000:
-		return "Python/Backend"
-	}
-
-	// Process-based detection
-	switch status.ProcessName {
-	case "postgres", "mysql", "mongod":
-		return "Database"
-	case "redis":
-		return "Cache"
-	case "node", "python", "java":
-		return "Application"
-	default:
-		return "System"
-	}
-}
-
 func (tf *TableFormatter) countConflicts(statuses []*scanner.PortStatus) int {
 	count := 0
 	for _, status := range statuses {
@@ -164,6 +165,12 @@ func (tf *TableFormatter) generateResolutions(statuses []*scanner.PortStatus) st
 
 	sb.WriteString(fmt.Sprintf("CONFLICT RESOLUTION (%d conflicts):\n", conflictCount))
 	sb.WriteString("\033[32m1. PORT MAPPING\033[0m: Use alternative ports    ✅ RECOMMENDED\n")
+	alternatives := suggestAlternatives(statuses, tf.bindAddress)
+	for _, status := range statuses {
+		if !status.IsAvailable {
+			sb.WriteString(fmt.Sprintf("   %s\n", formatMapping(status.Spec(), alternatives[status.Spec()])))
+		}
+	}
 	sb.WriteString("\033[33m2. SERVICE RESTART\033[0m: Restart on new ports   ⚠️  LOW RISK\n")
 	sb.WriteString("\033[31m3. PROCESS TERMINATION\033[0m: Stop services      🔴 HIGH RISK\n")
 	sb.WriteString("\n\033[36mExecute: port-scanner --fix for auto-resolution\033[0m\n")
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"portscanner/formatter"
+	"portscanner/scanner"
+)
+
+// listFilter narrows --all down to the ports matching every set field
+type listFilter struct {
+	user    string
+	tech    string
+	minPort int // 0 when --port-range is not set
+	maxPort int
+}
+
+func parseListFilter(user, tech, portRange string) (listFilter, error) {
+	filter := listFilter{user: user, tech: tech}
+	if portRange == "" {
+		return filter, nil
+	}
+
+	startStr, endStr, _ := strings.Cut(portRange, "-")
+	if endStr == "" {
+		endStr = startStr
+	}
+	start, err1 := strconv.Atoi(startStr)
+	end, err2 := strconv.Atoi(endStr)
+	if err1 != nil || err2 != nil || start < 1 || end > 65535 || start > end {
+		return filter, fmt.Errorf("invalid port range: %s", portRange)
+	}
+	filter.minPort, filter.maxPort = start, end
+	return filter, nil
+}
+
+func (lf listFilter) inRange(spec scanner.PortSpec) bool {
+	return lf.minPort == 0 || (spec.Port >= lf.minPort && spec.Port <= lf.maxPort)
+}
+
+// matches checks the owner filters. Any process involved in holding the
+// port counts for --user; --tech matches the technology or the framework.
+func (lf listFilter) matches(status *scanner.PortStatus) bool {
+	if lf.user != "" && status.User != lf.user {
+		owned := false
+		for _, owner := range status.Owners {
+			owned = owned || owner.User == lf.user
+		}
+		if !owned {
+			return false
+		}
+	}
+	if lf.tech != "" {
+		analysis := status.Analysis
+		if analysis == nil || (!strings.EqualFold(analysis.Technology, lf.tech) && !strings.EqualFold(analysis.Framework, lf.tech)) {
+			return false
+		}
+	}
+	return true
+}
+
+// listPorts reports every listening port on the machine, the --all mode
+func listPorts(ps scanner.PortScanner, format, projectName string, filter listFilter) int {
+	startedAt := time.Now()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	specs, err := ps.Listening(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not list listening ports: %v\n", err)
+		return exitScanError
+	}
+	specs = slices.DeleteFunc(specs, func(spec scanner.PortSpec) bool { return !filter.inRange(spec) })
+
+	// Checking each port again resolves owners exactly like a normal scan
+	statuses, err := ps.CheckPorts(ctx, specs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not check listening ports: %v\n", err)
+		return exitScanError
+	}
+	// Ports closed since the listing are no longer interesting
+	statuses = slices.DeleteFunc(statuses, func(status *scanner.PortStatus) bool { return status.IsAvailable })
+
+	analyzeOwners(statuses)
+	statuses = slices.DeleteFunc(statuses, func(status *scanner.PortStatus) bool { return !filter.matches(status) })
+
+	switch format {
+	case "json":
+		printJSONOutput(statuses, scanMetadata(projectName, "", ps.Backend(), startedAt))
+	case "simple":
+		printSimpleOutput(statuses)
+	case "detailed":
+		printDetailedOutput(statuses, projectName, "")
+	default:
+		formatter := formatter.NewListFormatter()
+		fmt.Println(formatter.ProjectTable(statuses))
+	}
+
+	// Listening ports are what was asked for, not conflicts
+	return exitOK
+}
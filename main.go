@@ -1,23 +1,47 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"portscanner/fixer"
 	"portscanner/formatter"
 	"portscanner/scanner"
 )
 
+const version = "1.0.0"
+
 func main() {
 	// Define flags
 	var (
-		format      = flag.String("format", "table", "Output format: table, detailed, or simple")
-		project     = flag.String("project", "project", "Project name for analysis")
+		format      = flag.String("format", "table", "Output format: table, detailed, simple, or json")
+		projectFlag = flag.String("project", "project", "Project name for analysis")
 		showHelp    = flag.Bool("help", false, "Show help message")
 		showVersion = flag.Bool("version", false, "Show version")
+		fix         = flag.Bool("fix", false, "Interactively resolve conflicts")
+		dryRun      = flag.Bool("dry-run", false, "With --fix, show what would be done without doing it")
+		assumeYes   = flag.Bool("yes", false, "With --fix, apply --policy without prompting")
+		policy      = flag.String("policy", fixer.PolicyRemap, "Policy for --fix --yes: remap, stop, or skip")
+		stopParent  = flag.Bool("stop-parent", false, "With --fix, also stop the process that launched the port's owner (e.g. npm)")
+		bind        = flag.String("bind", "", "Check availability on this local address only (e.g. 127.0.0.1 or ::1)")
+		backend     = flag.String("backend", scanner.BackendAuto, "Socket backend: auto, netlink, proc, ss, or lsof")
+		autoDetect  = flag.Bool("auto-detect", false, "Also check the ports the project's files declare (package.json, compose, .env, ...)")
+		all         = flag.Bool("all", false, "List every listening port instead of checking given ones")
+		user        = flag.String("user", "", "With --all, only ports owned by this user")
+		tech        = flag.String("tech", "", "With --all, only ports of this technology or framework (e.g. node, django)")
+		portRange   = flag.String("port-range", "", "With --all, only ports in this range (e.g. 3000-9000)")
 	)
+	expectations := expectFlag{}
+	flag.Var(expectations, "expect", "Assert a port state: PORT=free or PORT=in-use (repeatable)")
 
 	// Custom usage function
 	flag.Usage = func() {
@@ -27,6 +51,18 @@ func main() {
 	// Parse flags
 	flag.Parse()
 
+	// Subcommands: "list" is --all, "pid" and "who" look up processes,
+	// "compose" checks a compose project
+	args := flag.Args()
+	subcommand := ""
+	if len(args) > 0 && slices.Contains([]string{"list", "pid", "who", "compose"}, args[0]) {
+		subcommand = args[0]
+		args = subcommandArgs(args[1:])
+	}
+	if subcommand == "list" {
+		*all = true
+	}
+
 	// Handle help flag
 	if *showHelp {
 		printUsage()
@@ -35,64 +71,195 @@ func main() {
 
 	// Handle version flag
 	if *showVersion {
-		fmt.Println("Port Scanner v1.0.0")
+		fmt.Printf("Port Scanner v%s\n", version)
 		return
 	}
 
+	// Validate format
+	validFormats := map[string]bool{"table": true, "detailed": true, "simple": true, "json": true}
+	if !validFormats[*format] {
+		fmt.Printf("❌ Invalid format: %s. Use table, detailed, simple, or json\n", *format)
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	// Validate backend
+	if !slices.Contains(scanner.Backends(), *backend) {
+		fmt.Printf("❌ Invalid backend: %s. Use %s\n", *backend, strings.Join(scanner.Backends(), ", "))
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	// List every listening port
+	if *all {
+		if len(args) > 0 || len(expectations) > 0 || *fix || *bind != "" || *autoDetect {
+			fmt.Println("❌ --all lists every port: it takes no ports, --expect, --fix, --bind or --auto-detect")
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		filter, err := parseListFilter(*user, *tech, *portRange)
+		if err != nil {
+			fmt.Printf("❌ %v. Use a range such as 3000-9000\n", err)
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		ps := scanner.NewScanner(scanner.Options{Backend: selectBackend(*backend)})
+		os.Exit(listPorts(ps, *format, *projectFlag, filter))
+	}
+	if *user != "" || *tech != "" || *portRange != "" {
+		fmt.Println("❌ --user, --tech and --port-range only apply to --all")
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	// Reverse lookup: the ports held by a PID or by every process with a name
+	if subcommand == "pid" || subcommand == "who" {
+		if len(args) != 1 || len(expectations) > 0 || *fix || *bind != "" || *autoDetect {
+			fmt.Printf("❌ Usage: port-scanner pid <PID> or port-scanner who <process name>, without --expect, --fix, --bind or --auto-detect\n")
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		os.Exit(lookupProcesses(subcommand, args[0], *format, *projectFlag))
+	}
+
+	// Preflight for docker compose up: args are compose files
+	if subcommand == "compose" {
+		if len(expectations) > 0 || *fix || *bind != "" || *autoDetect {
+			fmt.Println("❌ compose checks the ports its files publish: it takes no --expect, --fix, --bind or --auto-detect")
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		os.Exit(checkCompose(args, *format, selectBackend(*backend), *projectFlag))
+	}
+
+	// The project manifest and --auto-detect name the project and, without
+	// port arguments, say which ports to check
+	project, err := loadProject(*projectFlag, *autoDetect, len(args) > 0)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	// Get remaining arguments (ports)
-	args := flag.Args()
-	if len(args) == 0 {
-		fmt.Println("❌ No ports provided")
+	requested := project.ports(args)
+	if len(args) == 0 && len(requested) == 0 && len(expectations) == 0 {
+		if *autoDetect {
+			fmt.Println("❌ No ports provided, and none found in the project files")
+		} else {
+			fmt.Println("❌ No ports provided")
+		}
 		printUsage()
-		return
+		os.Exit(exitUsage)
 	}
 
-	// Parse ports from remaining arguments
-	ports := parsePorts(args)
+	// Parse ports from remaining arguments, plus any only named in --expect
+	ports := addExpectedPorts(requested, expectations)
 	if len(ports) == 0 {
 		fmt.Println("❌ No valid ports provided")
 		printUsage()
-		return
+		os.Exit(exitUsage)
 	}
 
-	// Validate format
-	validFormats := map[string]bool{"table": true, "detailed": true, "simple": true}
-	if !validFormats[*format] {
-		fmt.Printf("❌ Invalid format: %s. Use table, detailed, or simple\n", *format)
+	// Validate fix options
+	if (*dryRun || *assumeYes || *stopParent) && !*fix {
+		fmt.Println("❌ --dry-run, --yes and --stop-parent only apply together with --fix")
 		printUsage()
-		return
+		os.Exit(exitUsage)
+	}
+	if !fixer.ValidPolicy(*policy) {
+		fmt.Printf("❌ Invalid policy: %s. Use remap, stop, or skip\n", *policy)
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	// Validate bind address
+	if *bind != "" && net.ParseIP(*bind) == nil {
+		fmt.Printf("❌ Invalid bind address: %s. Use an IP such as 127.0.0.1 or ::1\n", *bind)
+		printUsage()
+		os.Exit(exitUsage)
+	}
+
+	ps := scanner.NewScanner(scanner.Options{BindAddress: *bind, Backend: selectBackend(*backend)})
+	statuses := scanPorts(ps, ports, *format, *bind, project)
+
+	if *fix {
+		runFixer(ps, statuses, expectations, fixer.Options{
+			DryRun:     *dryRun,
+			Yes:        *assumeYes,
+			Policy:     *policy,
+			StopParent: *stopParent,
+			Out:        fixerOutput(*format),
+		})
 	}
 
-	scanPorts(ports, *format, *project)
+	os.Exit(exitCode(statuses, expectations))
 }
 
-func parsePorts(args []string) []int {
-	var ports []int
+// subcommandArgs parses the flags given after a subcommand, wherever they
+// appear among its operands, and returns the operands
+func subcommandArgs(args []string) []string {
+	var operands []string
+	for len(args) > 0 {
+		flag.CommandLine.Parse(args)
+		args = flag.Args()
+		if len(args) > 0 {
+			operands = append(operands, args[0])
+			args = args[1:]
+		}
+	}
+	return operands
+}
+
+func parsePorts(args []string) []scanner.PortSpec {
+	var ports []scanner.PortSpec
 
 	for _, arg := range args {
+		// Split off a protocol suffix (e.g., 53/udp), TCP by default
+		portStr, protocol, err := splitProtocol(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping invalid port: %s (%v)\n", arg, err)
+			continue
+		}
+
 		// Check for port ranges (e.g., 3000-3010)
-		if strings.Contains(arg, "-") {
-			rangePorts := parsePortRange(arg)
+		if strings.Contains(portStr, "-") {
+			rangePorts := parsePortRange(portStr, protocol)
 			ports = append(ports, rangePorts...)
 			continue
 		}
 
 		// Single port
-		port, err := strconv.Atoi(arg)
+		port, err := strconv.Atoi(portStr)
 		if err != nil || port < 1 || port > 65535 {
-			fmt.Printf("⚠️  Skipping invalid port: %s\n", arg)
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping invalid port: %s\n", arg)
 			continue
 		}
-		ports = append(ports, port)
+		ports = append(ports, scanner.PortSpec{Port: port, Protocol: protocol})
 	}
 	return ports
 }
 
-func parsePortRange(rangeStr string) []int {
-	var ports []int
+// splitProtocol turns "8125/udp" into ("8125", "udp"); no suffix means TCP
+func splitProtocol(arg string) (string, string, error) {
+	portStr, protocol, found := strings.Cut(arg, "/")
+	if !found {
+		return arg, scanner.ProtocolTCP, nil
+	}
+
+	switch strings.ToLower(protocol) {
+	case scanner.ProtocolTCP:
+		return portStr, scanner.ProtocolTCP, nil
+	case scanner.ProtocolUDP:
+		return portStr, scanner.ProtocolUDP, nil
+	}
+	return "", "", fmt.Errorf("unknown protocol %q, use tcp or udp", protocol)
+}
+
+func parsePortRange(rangeStr string, protocol string) []scanner.PortSpec {
+	var ports []scanner.PortSpec
 	parts := strings.Split(rangeStr, "-")
 	if len(parts) != 2 {
-		fmt.Printf("⚠️  Skipping invalid port range: %s\n", rangeStr)
+		fmt.Fprintf(os.Stderr, "⚠️  Skipping invalid port range: %s\n", rangeStr)
 		return ports
 	}
 
@@ -100,54 +267,165 @@ func parsePortRange(rangeStr string) []int {
 	end, err2 := strconv.Atoi(parts[1])
 
 	if err1 != nil || err2 != nil || start < 1 || end > 65535 || start > end {
-		fmt.Printf("⚠️  Skipping invalid port range: %s\n", rangeStr)
+		fmt.Fprintf(os.Stderr, "⚠️  Skipping invalid port range: %s\n", rangeStr)
 		return ports
 	}
 
 	for port := start; port <= end; port++ {
-		ports = append(ports, port)
+		ports = append(ports, scanner.PortSpec{Port: port, Protocol: protocol})
 	}
 
-	fmt.Printf("🔍 Added port range: %d-%d (%d ports)\n", start, end, end-start+1)
+	// Progress notes go to stderr so machine-readable output stays clean
+	fmt.Fprintf(os.Stderr, "🔍 Added port range: %d-%d/%s (%d ports)\n", start, end, protocol, end-start+1)
 	return ports
 }
 
+// flagSet reports whether the flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// selectBackend falls back along the backend chain, explaining on stderr
+// why a requested backend wasn't used
+func selectBackend(requested string) string {
+	backend, skipped := scanner.SelectBackend(requested)
+	if (requested != scanner.BackendAuto && backend != requested) || backend == scanner.BackendNone {
+		for _, err := range skipped {
+			fmt.Fprintf(os.Stderr, "⚠️  Backend %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  Using backend: %s\n", backend)
+	}
+	return backend
+}
+
 // The rest of your existing functions remain the same...
-func scanPorts(ports []int, format string, projectName string) {
-	ps := scanner.NewScanner()
+func scanPorts(ps scanner.PortScanner, ports []scanner.PortSpec, format, bindAddress string, project projectContext) []*scanner.PortStatus {
+	startedAt := time.Now()
 
-	var statuses []*scanner.PortStatus
-	for _, port := range ports {
-		status, err := ps.CheckPort(port)
-		if err != nil {
-			status = &scanner.PortStatus{
-				Port:  port,
-				Error: err.Error(),
+	// Ctrl-C stops a long range scan instead of leaving workers behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	statuses, err := ps.CheckPorts(ctx, ports)
+	if err != nil {
+		statuses = make([]*scanner.PortStatus, len(ports))
+		for i, spec := range ports {
+			statuses[i] = &scanner.PortStatus{
+				Port:      spec.Port,
+				Protocol:  spec.Protocol,
+				Error:     err.Error(),
+				ErrorCode: scanner.ErrCodeScanFailed,
 			}
 		}
-		statuses = append(statuses, status)
+	}
+
+	analyzeOwners(statuses)
+
+	project.label(statuses)
+	meta := scanMetadata(project.name, bindAddress, ps.Backend(), startedAt)
+	if project.manifest != nil {
+		meta.Manifest = project.manifest.Path
 	}
 
 	switch format {
+	case "json":
+		printJSONOutput(statuses, meta)
 	case "simple":
 		printSimpleOutput(statuses)
 	case "detailed":
-		printDetailedOutput(statuses, projectName)
+		printDetailedOutput(statuses, project.name, bindAddress)
 	case "table":
 		fallthrough
 	default:
-		printTableOutput(statuses, projectName)
+		printTableOutput(statuses, project.name, bindAddress)
+	}
+
+	return statuses
+}
+
+// analyzeOwners attaches a ProcessAnalysis to every occupied port.
+// A process holding several ports is only analyzed once.
+func analyzeOwners(statuses []*scanner.PortStatus) {
+	analyzer := scanner.NewProcessAnalyzer()
+	analyses := make(map[int]*scanner.ProcessAnalysis)
+
+	for _, status := range statuses {
+		if status.IsAvailable || status.PID == 0 {
+			continue
+		}
+
+		analysis, seen := analyses[status.PID]
+		if !seen {
+			var err error
+			analysis, err = analyzer.AnalyzeProcess(status.PID)
+			if err != nil {
+				// The process may have exited since the scan
+				analysis = nil
+			}
+			analyses[status.PID] = analysis
+		}
+		status.Analysis = analysis
 	}
 }
 
-func printDetailedOutput(statuses []*scanner.PortStatus, projectName string) {
-	formatter := formatter.NewDetailedFormatter()
+// runFixer offers a resolution for every conflict, leaving alone ports that
+// are expected to be in use
+func runFixer(ps scanner.PortScanner, statuses []*scanner.PortStatus, expectations expectFlag, opts fixer.Options) {
+	var conflicts []*scanner.PortStatus
+	for _, status := range statuses {
+		if !status.IsAvailable && expectations[status.Spec()] != expectInUse {
+			conflicts = append(conflicts, status)
+		}
+	}
+
+	f := fixer.NewFixer(ps, opts)
+	f.Run(conflicts)
+}
+
+// fixerOutput keeps prompts off stdout when stdout carries JSON
+func fixerOutput(format string) *os.File {
+	if format == "json" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func scanMetadata(projectName, bindAddress, backend string, startedAt time.Time) formatter.ScanMetadata {
+	hostname, _ := os.Hostname()
+	return formatter.ScanMetadata{
+		Version:     version,
+		Project:     projectName,
+		Hostname:    hostname,
+		OS:          runtime.GOOS,
+		BindAddress: bindAddress,
+		Backend:     backend,
+		StartedAt:   startedAt,
+		Duration:    time.Since(startedAt),
+	}
+}
+
+func printJSONOutput(statuses []*scanner.PortStatus, meta formatter.ScanMetadata) {
+	formatter := formatter.NewJSONFormatter()
+	output, err := formatter.Report(statuses, meta)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to encode JSON: %v\n", err)
+		return
+	}
+	fmt.Println(output)
+}
+
+func printDetailedOutput(statuses []*scanner.PortStatus, projectName, bindAddress string) {
+	formatter := formatter.NewDetailedFormatter(bindAddress)
 	output := formatter.DetailedTable(statuses, projectName)
 	fmt.Println(output)
 }
 
-func printTableOutput(statuses []*scanner.PortStatus, projectName string) {
-	formatter := formatter.NewTableFormatter()
+func printTableOutput(statuses []*scanner.PortStatus, projectName, bindAddress string) {
+	formatter := formatter.NewTableFormatter(bindAddress)
 	output := formatter.BriefTable(statuses, projectName)
 	fmt.Println(output)
 }
@@ -156,35 +434,113 @@ func printSimpleOutput(statuses []*scanner.PortStatus) {
 	fmt.Printf("🔍 Scanning %d port(s)...\n\n", len(statuses))
 
 	for _, status := range statuses {
-		if status.Error != "" {
-			fmt.Printf("🚨 Port %d: Error - %s\n", status.Port, status.Error)
+		port := status.Spec().String()
+		if status.Service != "" {
+			port += " (" + status.Service + ")"
+		}
+		if len(status.DetectedIn) > 0 {
+			port += " [" + strings.Join(status.DetectedIn, ", ") + "]"
+		}
+
+		if status.Error != "" && status.Container == nil {
+			fmt.Printf("🚨 Port %s: Error - %s\n", port, status.Error)
+		} else if status.Reason != "" && status.Reason != scanner.ReasonInUse {
+			reason, remedy := formatter.ExplainReason(status)
+			fmt.Printf("🚨 Port %s: %s - %s\n", port, reason, remedy)
 		} else if status.IsAvailable {
-			fmt.Printf("✅ Port %d: Available\n", status.Port)
+			fmt.Printf("✅ Port %s: Available\n", port)
 		} else {
-			fmt.Printf("🚨 Port %d: Occupied by %s (PID %d)\n",
-				status.Port, status.ProcessName, status.PID)
+			fmt.Printf("🚨 Port %s: Occupied by %s\n", port, describeOccupant(status))
 		}
 	}
 }
 
+// describeOccupant renders "node (PID 4521), shared with 3 workers" or,
+// for ports a Docker container publishes, "container shop-web-1 (nginx:1.25)"
+func describeOccupant(status *scanner.PortStatus) string {
+	if container := status.Container; container != nil {
+		return fmt.Sprintf("container %s (%s)", container.Name, container.Image)
+	}
+	return fmt.Sprintf("%s (PID %d)%s", status.ProcessName, status.PID, sharedWith(status))
+}
+
+// sharedWith renders ", shared with 3 workers" style suffixes
+func sharedWith(status *scanner.PortStatus) string {
+	counts := make(map[string]int)
+	for _, owner := range status.Owners[min(1, len(status.Owners)):] {
+		counts[owner.Relationship]++
+	}
+
+	var parts []string
+	for _, relationship := range []string{scanner.RelationListener, scanner.RelationWorker, scanner.RelationParent} {
+		if n := counts[relationship]; n > 0 {
+			name := relationship
+			if n > 1 {
+				name += "s"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", n, name))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", with " + strings.Join(parts, ", ")
+}
+
 func printUsage() {
 	fmt.Println("Port Scanner - Check if ports are available")
 	fmt.Println("")
-	fmt.Println("Usage: port-scanner [OPTIONS] <port1> <port2> ...")
+	fmt.Println("Usage: port-scanner [OPTIONS] [<port1> <port2> ...]")
+	fmt.Println("       port-scanner list [OPTIONS]   (same as --all)")
+	fmt.Println("       port-scanner pid <PID> [OPTIONS]")
+	fmt.Println("       port-scanner who <process name> [OPTIONS]")
+	fmt.Println("       port-scanner compose [<compose file> ...] [OPTIONS]")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  port-scanner 3000 5432 8080")
 	fmt.Println("  port-scanner --format detailed 3000 8501 5173")
 	fmt.Println("  port-scanner --project my-app 3000 5432")
 	fmt.Println("  port-scanner 3000-3010 8080-8085")
+	fmt.Println("  port-scanner --format json 3000 5432")
+	fmt.Println("  port-scanner 53/udp 8125/udp 3000")
+	fmt.Println("  port-scanner --bind 127.0.0.1 3000")
+	fmt.Println("  port-scanner --expect 5432=in-use --expect 3000=free")
+	fmt.Println("  port-scanner --fix --dry-run 3000 8080")
+	fmt.Println("  port-scanner list")
+	fmt.Println("  port-scanner list --tech node --port-range 3000-9000")
+	fmt.Println("  port-scanner pid 4521")
+	fmt.Println("  port-scanner who node --format json")
+	fmt.Println("  port-scanner compose")
+	fmt.Println("  port-scanner compose docker-compose.yml docker-compose.dev.yml")
 	fmt.Println("")
 	fmt.Println("Options:")
-	fmt.Println("  --format string    Output format: table, detailed, or simple (default: table)")
-	fmt.Println("  --project string   Project name for analysis (default: project)")
+	fmt.Println("  --format string    Output format: table, detailed, simple, or json (default: table)")
+	fmt.Println("  --project string   Project name for analysis (default: from .portscanner.yaml, else project)")
+	fmt.Println("  --expect PORT[/udp]=STATE  Assert a port is free or in-use (repeatable)")
+	fmt.Println("  --bind address     Check availability on one local address, e.g. 127.0.0.1 or ::1")
+	fmt.Println("  --backend string   Socket backend: auto, netlink, proc, ss, or lsof (default: auto)")
+	fmt.Println("  --auto-detect      Also check the ports declared in the project's files, citing file and line")
+	fmt.Println("  --all              List every listening TCP and UDP port, grouped by project")
+	fmt.Println("  --user string      With --all, only ports owned by this user")
+	fmt.Println("  --tech string      With --all, only this technology or framework (e.g. node, django)")
+	fmt.Println("  --port-range range With --all, only ports in this range (e.g. 3000-9000)")
+	fmt.Println("  --fix              Walk through conflicts: remap, stop the owner, or skip")
+	fmt.Println("  --dry-run          With --fix, only show what would be done")
+	fmt.Println("  --yes              With --fix, don't prompt; apply --policy to every conflict")
+	fmt.Println("  --policy string    Policy for --yes: remap, stop, or skip (default: remap)")
+	fmt.Println("  --stop-parent      With --fix, also stop the process that launched the owner (e.g. npm)")
 	fmt.Println("  --help             Show this help message")
 	fmt.Println("  --version          Show version information")
 	fmt.Println("")
 	fmt.Println("Ports can be specified as:")
 	fmt.Println("  • Single ports: 3000 5432 8080")
 	fmt.Println("  • Port ranges: 3000-3010 8080-8085")
+	fmt.Println("  • UDP ports: 53/udp 8125/udp 5000-5010/udp (TCP is the default)")
+	fmt.Println("  • Nothing: the ports declared in .portscanner.yaml, looked up from the current directory")
+	fmt.Println("")
+	fmt.Println("Exit codes:")
+	fmt.Println("  0  All ports free (or matching --expect)")
+	fmt.Println("  1  A port is occupied, or an --expect assertion failed")
+	fmt.Println("  2  Usage error")
+	fmt.Println("  3  A port could not be checked")
 }
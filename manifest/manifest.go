@@ -0,0 +1,191 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"portscanner/scanner"
+)
+
+// FileNames are looked for in every directory from the working directory up
+var FileNames = []string{".portscanner.yaml", ".portscanner.yml"}
+
+// Manifest is a parsed .portscanner.yaml:
+//
+//	project: shop
+//	services:
+//	  - name: web
+//	    ports: [3000, {port: 5173, optional: true}]
+//	  - name: statsd
+//	    ports: ["8125/udp"]
+type Manifest struct {
+	Project  string    `yaml:"project"`
+	Services []Service `yaml:"services"`
+
+	Path string `yaml:"-"` // file the manifest was read from
+}
+
+type Service struct {
+	Name  string `yaml:"name"`
+	Ports []Port `yaml:"ports"`
+}
+
+// Port is one port a service binds. It is written either as a mapping or
+// as a scalar such as 3000 or "8125/udp".
+type Port struct {
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol"` // scanner.ProtocolTCP (default) or scanner.ProtocolUDP
+	Optional bool   `yaml:"optional"` // the service still works when the port is taken
+}
+
+func (p *Port) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		portStr, protocol, _ := strings.Cut(node.Value, "/")
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid port %q", node.Line, node.Value)
+		}
+		*p = Port{Port: port, Protocol: protocol}
+		return nil
+	}
+
+	// node.Decode doesn't inherit KnownFields, so check the keys here
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if !slices.Contains(portFields, key.Value) {
+				return fmt.Errorf("line %d: unknown port field %q, use %s", key.Line, key.Value, strings.Join(portFields, ", "))
+			}
+		}
+	}
+
+	type plain Port // without UnmarshalYAML
+	return node.Decode((*plain)(p))
+}
+
+var portFields = []string{"port", "protocol", "optional"}
+
+// Spec returns the port and protocol
+func (p Port) Spec() scanner.PortSpec {
+	return scanner.PortSpec{Port: p.Port, Protocol: p.Protocol}
+}
+
+// Find walks up from dir to the filesystem root and loads the first
+// manifest found. It returns nil and no error when there is none.
+func Find(dir string) (*Manifest, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return Load(path)
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir { // Reached root
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates one manifest file
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown keys are most likely typos, such as "optinal: true"
+	m := &Manifest{Path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// validate normalizes protocols and rejects ports two services both claim
+func (m *Manifest) validate() error {
+	if len(m.Services) == 0 {
+		return errors.New("no services declared")
+	}
+	if m.Project == "" {
+		m.Project = filepath.Base(filepath.Dir(m.Path))
+	}
+
+	claimed := make(map[scanner.PortSpec]string)
+	for i := range m.Services {
+		service := &m.Services[i]
+		if service.Name == "" {
+			return fmt.Errorf("service %d has no name", i+1)
+		}
+		if len(service.Ports) == 0 {
+			return fmt.Errorf("service %s declares no ports", service.Name)
+		}
+
+		for j := range service.Ports {
+			port := &service.Ports[j]
+			switch strings.ToLower(port.Protocol) {
+			case "", scanner.ProtocolTCP:
+				port.Protocol = scanner.ProtocolTCP
+			case scanner.ProtocolUDP:
+				port.Protocol = scanner.ProtocolUDP
+			default:
+				return fmt.Errorf("service %s: unknown protocol %q, use tcp or udp", service.Name, port.Protocol)
+			}
+			if port.Port < 1 || port.Port > 65535 {
+				return fmt.Errorf("service %s: invalid port %d", service.Name, port.Port)
+			}
+
+			if other, ok := claimed[port.Spec()]; ok {
+				return fmt.Errorf("port %s is declared by both %s and %s", port.Spec(), other, service.Name)
+			}
+			claimed[port.Spec()] = service.Name
+		}
+	}
+	return nil
+}
+
+// Specs returns every declared port in declaration order
+func (m *Manifest) Specs() []scanner.PortSpec {
+	var specs []scanner.PortSpec
+	for _, service := range m.Services {
+		for _, port := range service.Ports {
+			specs = append(specs, port.Spec())
+		}
+	}
+	return specs
+}
+
+// Label sets Service and Optional on the statuses of declared ports
+func (m *Manifest) Label(statuses []*scanner.PortStatus) {
+	for _, status := range statuses {
+		for _, service := range m.Services {
+			for _, port := range service.Ports {
+				if port.Spec() == status.Spec() {
+					status.Service = service.Name
+					status.Optional = port.Optional
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"portscanner/scanner"
+)
+
+// writeManifest writes data as dir/name, creating dir
+func writeManifest(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tcp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP} }
+	udp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolUDP} }
+
+	tests := []struct {
+		name    string
+		data    string
+		project string
+		specs   []scanner.PortSpec
+		wantErr string // substring of the error, "" for none
+	}{
+		{
+			name:    "scalar ports",
+			data:    "project: shop\nservices:\n  - name: web\n    ports: [3000, \"3001\"]\n",
+			project: "shop",
+			specs:   []scanner.PortSpec{tcp(3000), tcp(3001)},
+		},
+		{
+			name:    "mapping entry",
+			data:    "services:\n  - name: web\n    ports:\n      - port: 5173\n        optional: true\n      - {port: 8125, protocol: UDP}\n",
+			project: "repo", // the manifest's directory
+			specs:   []scanner.PortSpec{tcp(5173), udp(8125)},
+		},
+		{
+			name:    "protocol suffix",
+			data:    "services:\n  - name: statsd\n    ports: [\"8125/udp\", \"9000/tcp\"]\n",
+			project: "repo",
+			specs:   []scanner.PortSpec{udp(8125), tcp(9000)},
+		},
+		{
+			name:    "same port over tcp and udp",
+			data:    "services:\n  - name: dns\n    ports: [53, \"53/udp\"]\n",
+			project: "repo",
+			specs:   []scanner.PortSpec{tcp(53), udp(53)},
+		},
+		{name: "duplicate port", data: "services:\n  - name: web\n    ports: [3000]\n  - name: api\n    ports: [3000]\n",
+			wantErr: "port 3000/tcp is declared by both web and api"},
+		{name: "port zero", data: "services:\n  - name: web\n    ports: [0]\n", wantErr: "invalid port 0"},
+		{name: "port too high", data: "services:\n  - name: web\n    ports: [{port: 65536}]\n", wantErr: "invalid port 65536"},
+		{name: "not a port", data: "services:\n  - name: web\n    ports: [http]\n", wantErr: `invalid port "http"`},
+		{name: "unknown protocol", data: "services:\n  - name: web\n    ports: [\"3000/sctp\"]\n", wantErr: `unknown protocol "sctp"`},
+		{name: "unknown top-level key", data: "project: shop\nservice:\n  - name: web\n", wantErr: "field service not found"},
+		{name: "unknown service key", data: "services:\n  - name: web\n    port: [3000]\n", wantErr: "field port not found"},
+		{name: "unknown port key", data: "services:\n  - name: web\n    ports:\n      - port: 3000\n        optinal: true\n", wantErr: `unknown port field "optinal"`},
+		{name: "no services", data: "project: shop\n", wantErr: "no services declared"},
+		{name: "empty file", data: "", wantErr: "no services declared"},
+		{name: "service without a name", data: "services:\n  - ports: [3000]\n", wantErr: "service 1 has no name"},
+		{name: "service without ports", data: "services:\n  - name: web\n", wantErr: "service web declares no ports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeManifest(t, filepath.Join(t.TempDir(), "repo"), ".portscanner.yaml", tt.data)
+
+			m, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if m.Project != tt.project {
+				t.Errorf("Load() project = %q, want %q", m.Project, tt.project)
+			}
+			if got := m.Specs(); !slices.Equal(got, tt.specs) {
+				t.Errorf("Specs() = %v, want %v", got, tt.specs)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	rootManifest := writeManifest(t, root, ".portscanner.yaml", "services:\n  - name: web\n    ports: [3000]\n")
+	nested := filepath.Join(root, "services", "api", "cmd")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	apiManifest := writeManifest(t, filepath.Join(root, "services", "api"), ".portscanner.yml", "services:\n  - name: api\n    ports: [4000]\n")
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"in the repository root", root, rootManifest},
+		{"walks up to the repository root", filepath.Join(root, "services"), rootManifest},
+		{"nearest manifest wins", nested, apiManifest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Find(tt.dir)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if m == nil || m.Path != tt.want {
+				t.Errorf("Find() = %+v, want %s", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+	path := writeManifest(t, t.TempDir(), ".portscanner.yaml",
+		"services:\n  - name: web\n    ports: [3000, {port: 5173, optional: true}]\n  - name: statsd\n    ports: [\"8125/udp\"]\n")
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := []*scanner.PortStatus{
+		{Port: 5173, Protocol: scanner.ProtocolTCP},
+		{Port: 8125, Protocol: scanner.ProtocolUDP},
+		{Port: 8125, Protocol: scanner.ProtocolTCP},
+	}
+	m.Label(statuses)
+
+	want := []struct {
+		service  string
+		optional bool
+	}{{"web", true}, {"statsd", false}, {"", false}}
+	for i, status := range statuses {
+		if status.Service != want[i].service || status.Optional != want[i].optional {
+			t.Errorf("%s labelled %q (optional %v), want %q (optional %v)",
+				status.Spec(), status.Service, status.Optional, want[i].service, want[i].optional)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"portscanner/formatter"
+	"portscanner/scanner"
+)
+
+// lookupProcesses reports the ports held by one PID ("pid 1234") or by
+// every process with a name ("who node")
+func lookupProcesses(subcommand, target, format, projectName string) int {
+	startedAt := time.Now()
+	analyzer := scanner.NewProcessAnalyzer()
+
+	var pids []int
+	if subcommand == "pid" {
+		pid, err := strconv.Atoi(target)
+		if err != nil || pid < 1 {
+			fmt.Printf("❌ Invalid PID: %s\n", target)
+			printUsage()
+			return exitUsage
+		}
+		pids = []int{pid}
+	} else {
+		found, err := analyzer.FindProcesses(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Could not list processes: %v\n", err)
+			return exitScanError
+		}
+		pids = found
+	}
+
+	var analyses []*scanner.ProcessAnalysis
+	for _, pid := range pids {
+		analysis, err := analyzer.AnalyzeProcess(pid)
+		if err != nil {
+			// The process may have exited since it was found
+			continue
+		}
+		analyses = append(analyses, analysis)
+	}
+	if len(analyses) == 0 {
+		if subcommand == "pid" {
+			fmt.Fprintf(os.Stderr, "❌ No process with PID %s\n", target)
+		} else {
+			fmt.Fprintf(os.Stderr, "❌ No process named %s\n", target)
+		}
+		return exitScanError
+	}
+
+	switch format {
+	case "json":
+		formatter := formatter.NewJSONFormatter()
+		output, err := formatter.ProcessReport(analyses, scanMetadata(projectName, "", "", startedAt))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to encode JSON: %v\n", err)
+			return exitScanError
+		}
+		fmt.Println(output)
+	case "simple":
+		printProcessesSimple(analyses)
+	default:
+		formatter := formatter.NewProcessFormatter()
+		fmt.Println(formatter.ProcessTable(analyses, target))
+	}
+	return exitOK
+}
+
+// printProcessesSimple prints one line per process:
+// "🔍 node (PID 4521): listening on 3000/tcp; connected to 5432/tcp"
+func printProcessesSimple(analyses []*scanner.ProcessAnalysis) {
+	for _, analysis := range analyses {
+		var listening, connected []string
+		seen := make(map[string]bool)
+		for _, socket := range analysis.Sockets {
+			spec := scanner.PortSpec{Port: socket.LocalPort, Protocol: socket.Protocol}
+			if !socket.Listening {
+				if socket.RemotePort == 0 {
+					continue
+				}
+				spec.Port = socket.RemotePort
+			}
+			name := spec.String()
+			key := fmt.Sprint(socket.Listening, name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if socket.Listening {
+				listening = append(listening, name)
+			} else {
+				connected = append(connected, name)
+			}
+		}
+
+		var parts []string
+		if len(listening) > 0 {
+			parts = append(parts, "listening on "+strings.Join(listening, ", "))
+		}
+		if len(connected) > 0 {
+			parts = append(parts, "connected to "+strings.Join(connected, ", "))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "no sockets found")
+		}
+		fmt.Printf("🔍 %s (PID %d): %s\n", analysis.Name, analysis.PID, strings.Join(parts, "; "))
+	}
+}
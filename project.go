@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"portscanner/autodetect"
+	"portscanner/manifest"
+	"portscanner/scanner"
+)
+
+// projectContext is what is known about the project being scanned
+type projectContext struct {
+	name     string
+	manifest *manifest.Manifest   // nil without a .portscanner.yaml
+	findings []autodetect.Finding // --auto-detect results
+}
+
+// loadProject finds the manifest from the current directory and, with
+// --auto-detect, reads the ports the project's files declare. The project
+// name comes from --project, then the manifest, then the project root.
+func loadProject(projectFlag string, autoDetect, portsGiven bool) (projectContext, error) {
+	project := projectContext{name: projectFlag}
+
+	m, err := findManifest(".", portsGiven)
+	if err != nil {
+		return project, err
+	}
+	project.manifest = m
+
+	var root string
+	if autoDetect {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return project, err
+		}
+		root, _ = scanner.NewProcessAnalyzer().FindProjectRoot(cwd)
+		project.findings = autodetect.Detect(root)
+		fmt.Fprintf(os.Stderr, "🔍 Detected %d port(s) in %s\n", len(autodetect.Specs(project.findings)), root)
+	}
+
+	if !flagSet("project") {
+		if m != nil {
+			project.name = m.Project
+		} else if root != "" {
+			project.name = filepath.Base(root)
+		}
+	}
+	return project, nil
+}
+
+// findManifest looks for the manifest from dir up. An invalid one is only
+// an error when it would say which ports to check: with portsGiven it is
+// skipped with a warning.
+func findManifest(dir string, portsGiven bool) (*manifest.Manifest, error) {
+	m, err := manifest.Find(dir)
+	if err == nil {
+		return m, nil
+	}
+	if !portsGiven {
+		return nil, fmt.Errorf("invalid project manifest: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  Ignoring invalid project manifest: %v\n", err)
+	return nil, nil
+}
+
+// ports returns what to check: the command line ports, or the manifest's
+// without any, plus the ports --auto-detect found
+func (pc projectContext) ports(args []string) []scanner.PortSpec {
+	var ports []scanner.PortSpec
+	if len(args) == 0 && pc.manifest != nil {
+		ports = pc.manifest.Specs()
+		fmt.Fprintf(os.Stderr, "📋 Checking %d port(s) declared in %s\n", len(ports), pc.manifest.Path)
+	} else {
+		ports = parsePorts(args)
+	}
+
+	for _, spec := range autodetect.Specs(pc.findings) {
+		if !slices.Contains(ports, spec) {
+			ports = append(ports, spec)
+		}
+	}
+	return ports
+}
+
+// label adds manifest service names and detection sources to statuses
+func (pc projectContext) label(statuses []*scanner.PortStatus) {
+	if pc.manifest != nil {
+		pc.manifest.Label(statuses)
+	}
+	autodetect.Label(statuses, pc.findings)
+}
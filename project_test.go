@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"portscanner/autodetect"
+	"portscanner/manifest"
+	"portscanner/scanner"
+)
+
+func TestProjectPorts(t *testing.T) {
+	tcp := func(port int) scanner.PortSpec { return scanner.PortSpec{Port: port, Protocol: scanner.ProtocolTCP} }
+	m := &manifest.Manifest{
+		Path:     "/home/dev/shop/.portscanner.yaml",
+		Services: []manifest.Service{{Name: "web", Ports: []manifest.Port{{Port: 3000, Protocol: scanner.ProtocolTCP}}}},
+	}
+	findings := []autodetect.Finding{{Spec: tcp(5173), File: "vite.config.ts", Line: 2}, {Spec: tcp(3000), File: ".env", Line: 1}}
+
+	tests := []struct {
+		name    string
+		project projectContext
+		args    []string
+		want    []scanner.PortSpec
+	}{
+		{"manifest", projectContext{manifest: m}, nil, []scanner.PortSpec{tcp(3000)}},
+		{"arguments instead of the manifest", projectContext{manifest: m}, []string{"8080"}, []scanner.PortSpec{tcp(8080)}},
+		{"manifest plus detected", projectContext{manifest: m, findings: findings}, nil, []scanner.PortSpec{tcp(3000), tcp(5173)}},
+		{"arguments plus detected", projectContext{manifest: m, findings: findings}, []string{"8080"}, []scanner.PortSpec{tcp(8080), tcp(5173), tcp(3000)}},
+		{"detected only", projectContext{findings: findings}, nil, []scanner.PortSpec{tcp(5173), tcp(3000)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.project.ports(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("ports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindManifest(t *testing.T) {
+	valid := t.TempDir()
+	invalid := t.TempDir()
+	for dir, data := range map[string]string{
+		valid:   "services:\n  - name: web\n    ports: [3000]\n",
+		invalid: "services: []\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, ".portscanner.yaml"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		dir          string
+		portsGiven   bool
+		wantManifest bool
+		wantErr      string
+	}{
+		{"valid manifest supplies the ports", valid, false, true, ""},
+		{"valid manifest labels given ports", valid, true, true, ""},
+		{"invalid manifest would supply the ports", invalid, false, false, "invalid project manifest"},
+		{"invalid manifest with ports given", invalid, true, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := findManifest(tt.dir, tt.portsGiven)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("findManifest() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findManifest() error = %v", err)
+			}
+			if (m != nil) != tt.wantManifest {
+				t.Errorf("findManifest() = %+v, want a manifest: %v", m, tt.wantManifest)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Address families a port is bound on, see PortStatus.Family
+const (
+	FamilyIPv4 = "ipv4" // IPv4 addresses only, e.g. 127.0.0.1
+	FamilyIPv6 = "ipv6" // IPv6 addresses only, e.g. ::1
+	FamilyDual = "dual" // both, or an IPv6 wildcard that also accepts IPv4
+)
+
+// recordAddresses fills in Addresses and Family from the local addresses of
+// the sockets bound on status's port
+func recordAddresses(status *PortStatus, ips []net.IP) {
+	seen := make(map[string]bool)
+	for _, ip := range ips {
+		addr := ip.String()
+		if !seen[addr] {
+			seen[addr] = true
+			status.Addresses = append(status.Addresses, addr)
+		}
+	}
+	sort.Strings(status.Addresses)
+	status.Family = addressFamily(ips, v6WildcardIsDual())
+}
+
+// addressFamily reports which families ips cover. A socket bound on :: also
+// accepts IPv4 unless IPV6_V6ONLY is set; the per-socket option isn't visible
+// to us, so dualWildcard says what the system default is.
+func addressFamily(ips []net.IP, dualWildcard bool) string {
+	var ipv4, ipv6 bool
+	for _, ip := range ips {
+		switch {
+		case ip.To4() != nil: // includes ::ffff:a.b.c.d
+			ipv4 = true
+		case ip.IsUnspecified() && dualWildcard:
+			ipv4, ipv6 = true, true
+		default:
+			ipv6 = true
+		}
+	}
+
+	switch {
+	case ipv4 && ipv6:
+		return FamilyDual
+	case ipv4:
+		return FamilyIPv4
+	case ipv6:
+		return FamilyIPv6
+	}
+	return ""
+}
+
+// v6WildcardIsDual reads net.ipv6.bindv6only. Where it can't be read (macOS,
+// Windows) dual-stack wildcards are the default too.
+func v6WildcardIsDual() bool {
+	data, err := os.ReadFile(filepath.Join(procDir, "sys", "net", "ipv6", "bindv6only"))
+	if err != nil {
+		return true
+	}
+	return strings.TrimSpace(string(data)) == "0"
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wellKnownPorts are defaults of common services and dev servers. Suggesting
+// one of them would just move the conflict to the next time that service starts.
+var wellKnownPorts = map[int]string{
+	1433:  "mssql",
+	1521:  "oracle",
+	2375:  "docker",
+	2376:  "docker-tls",
+	2379:  "etcd",
+	2380:  "etcd-peer",
+	3000:  "node/rails dev",
+	3306:  "mysql",
+	3389:  "rdp",
+	4200:  "angular dev",
+	5000:  "flask / macOS AirPlay",
+	5173:  "vite dev",
+	5432:  "postgres",
+	5672:  "amqp",
+	5900:  "vnc",
+	6379:  "redis",
+	6443:  "kubernetes api",
+	7000:  "macOS AirPlay",
+	8000:  "django dev",
+	8080:  "http-alt",
+	8443:  "https-alt",
+	8501:  "streamlit",
+	9000:  "php-fpm / minio",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	9300:  "elasticsearch transport",
+	11211: "memcached",
+	15672: "rabbitmq management",
+	27017: "mongodb",
+}
+
+const (
+	privilegedPortMax = 1023
+	// IANA dynamic range, the default on macOS and Windows
+	defaultEphemeralLow  = 49152
+	defaultEphemeralHigh = 65535
+	// How far from the original port to look before giving up
+	maxAlternativeDistance = 1000
+)
+
+// AlternativePortFinder suggests verified free ports near a conflicting one,
+// free on the address the scan checked. Use one finder per report: a port it
+// has suggested is never suggested again.
+type AlternativePortFinder struct {
+	isFree        func(spec PortSpec) bool
+	ephemeralLow  int
+	ephemeralHigh int
+	reserved      map[int]bool
+}
+
+// NewAlternativePortFinder verifies candidates on bindAddress, or on every
+// address when it is empty, like --bind
+func NewAlternativePortFinder(bindAddress string) *AlternativePortFinder {
+	low, high := ephemeralPortRange()
+	return &AlternativePortFinder{
+		isFree:        func(spec PortSpec) bool { return canBind(bindAddress, spec) },
+		ephemeralLow:  low,
+		ephemeralHigh: high,
+		reserved:      make(map[int]bool),
+	}
+}
+
+// Reserve excludes ports from suggestions, e.g. every port already in the report
+func (apf *AlternativePortFinder) Reserve(ports ...int) {
+	for _, port := range ports {
+		apf.reserved[port] = true
+	}
+}
+
+// Find searches outward from original (+1, -1, +2, -2, ...) and returns the
+// first candidate that passes the filters and can be bound with the same protocol
+func (apf *AlternativePortFinder) Find(original PortSpec) (int, error) {
+	for distance := 1; distance <= maxAlternativeDistance; distance++ {
+		for _, candidate := range []int{original.Port + distance, original.Port - distance} {
+			if !apf.eligible(candidate) {
+				continue
+			}
+			if apf.isFree(PortSpec{Port: candidate, Protocol: original.Protocol}) {
+				apf.reserved[candidate] = true
+				return candidate, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no free port within %d of %d", maxAlternativeDistance, original.Port)
+}
+
+func (apf *AlternativePortFinder) eligible(port int) bool {
+	if port <= privilegedPortMax || port > 65535 {
+		return false
+	}
+	if port >= apf.ephemeralLow && port <= apf.ephemeralHigh {
+		return false
+	}
+	if _, known := wellKnownPorts[port]; known {
+		return false
+	}
+	return !apf.reserved[port]
+}
+
+// ephemeralPortRange returns the range the kernel hands out for outgoing
+// connections. Linux exposes it in /proc; elsewhere the IANA default is used.
+func ephemeralPortRange() (int, int) {
+	data, err := os.ReadFile(filepath.Join(procDir, "sys", "net", "ipv4", "ip_local_port_range"))
+	if err != nil {
+		return defaultEphemeralLow, defaultEphemeralHigh
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return defaultEphemeralLow, defaultEphemeralHigh
+	}
+	low, err1 := strconv.Atoi(fields[0])
+	high, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || low > high {
+		return defaultEphemeralLow, defaultEphemeralHigh
+	}
+	return low, high
+}
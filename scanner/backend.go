@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Socket data backends
+const (
+	BackendAuto    = "auto"    // the first backend in backendChain that works
+	BackendNetlink = "netlink" // NETLINK_SOCK_DIAG, one binary dump per family (Linux)
+	BackendProc    = "proc"    // /proc/net text tables (Linux)
+	BackendSS      = "ss"      // iproute2's ss, for hosts that hide /proc details (Linux)
+	BackendLsof    = "lsof"    // lsof and ps (macOS, or Linux hosts without /proc access)
+	BackendNone    = "none"    // nothing works: bind checks only, no owners
+)
+
+var errNoBackend = errors.New("no socket backend available (see --backend)")
+
+// backendChain lists the backends from best to worst: netlink is a single
+// binary round trip, /proc needs text parsing, ss forks one process and
+// lsof two
+var backendChain = []struct {
+	name  string
+	probe func() error
+}{
+	{BackendNetlink, probeNetlink},
+	{BackendProc, probeProc},
+	{BackendSS, probeSS},
+	{BackendLsof, probeLsof},
+}
+
+// Backends returns the names accepted by SelectBackend
+func Backends() []string {
+	names := []string{BackendAuto}
+	for _, backend := range backendChain {
+		names = append(names, backend.name)
+	}
+	return names
+}
+
+// SelectBackend returns requested when it works on this host, otherwise the
+// best backend that does, or BackendNone. skipped explains every backend
+// that was tried and rejected on the way.
+func SelectBackend(requested string) (backend string, skipped []error) {
+	tried := make(map[string]bool)
+	if requested != "" && requested != BackendAuto {
+		tried[requested] = true
+		if err := probeBackend(requested); err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", requested, err))
+		} else {
+			return requested, nil
+		}
+	}
+
+	for _, candidate := range backendChain {
+		if tried[candidate.name] {
+			continue
+		}
+		if err := candidate.probe(); err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", candidate.name, err))
+			continue
+		}
+		return candidate.name, skipped
+	}
+	return BackendNone, skipped
+}
+
+func probeBackend(name string) error {
+	for _, candidate := range backendChain {
+		if candidate.name == name {
+			return candidate.probe()
+		}
+	}
+	return fmt.Errorf("unknown backend")
+}
+
+func probeProc() error {
+	file, err := os.Open(filepath.Join(procDir, "net", "tcp"))
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func probeLsof() error {
+	for _, tool := range []string{"lsof", "ps"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxBindWorkers bounds how many bind checks run at once
+const maxBindWorkers = 64
+
+// checkAvailability runs bind for every spec on a bounded worker pool.
+// bindErrs[i] is the result for specs[i], nil when the port is free.
+func checkAvailability(ctx context.Context, specs []PortSpec, bind func(PortSpec) error) ([]error, error) {
+	bindErrs := make([]error, len(specs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(maxBindWorkers, len(specs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				bindErrs[i] = bind(specs[i])
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range specs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return bindErrs, err
+}
+
+// tryBind binds spec on host, or on all addresses when host is empty: a
+// listener for TCP, a packet socket for UDP
+func tryBind(host string, spec PortSpec) error {
+	address := net.JoinHostPort(host, strconv.Itoa(spec.Port))
+	if spec.Protocol == ProtocolUDP {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return ln.Close()
+}
+
+func canBind(host string, spec PortSpec) bool {
+	return tryBind(host, spec) == nil
+}
+
+// ownerDetails is what PortStatus shows about an owning process. Scanners
+// resolve it once per PID, however many ports the process holds.
+type ownerDetails struct {
+	ppid        int
+	name        string
+	user        string
+	commandLine string
+	memoryUsage string
+	memoryBytes int64
+	startTime   string
+	started     time.Time
+}
+
+func (od *ownerDetails) apply(status *PortStatus) {
+	status.ProcessName = od.name
+	status.User = od.user
+	status.CommandLine = od.commandLine
+	status.MemoryUsage = od.memoryUsage
+	status.MemoryBytes = od.memoryBytes
+	status.StartTime = od.startTime
+	status.Started = od.started
+}
+
+func (od *ownerDetails) owner(pid int, relationship string) Owner {
+	return Owner{
+		PID:          pid,
+		PPID:         od.ppid,
+		Name:         od.name,
+		User:         od.user,
+		CommandLine:  od.commandLine,
+		Relationship: relationship,
+	}
+}
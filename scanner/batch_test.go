@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// benchmarkBasePort starts the benchmarked ranges well above the usual dev
+// server ports and below the Linux ephemeral range
+const benchmarkBasePort = 20000
+
+func tcpRange(first, count int) []PortSpec {
+	specs := make([]PortSpec, count)
+	for i := range specs {
+		specs[i] = PortSpec{Port: first + i, Protocol: ProtocolTCP}
+	}
+	return specs
+}
+
+// holdPorts listens on every spec for the rest of the benchmark, so the
+// scanner has owners to resolve: this process
+func holdPorts(tb testing.TB, specs []PortSpec) {
+	tb.Helper()
+	for _, spec := range specs {
+		ln, err := net.Listen("tcp", ":"+strconv.Itoa(spec.Port))
+		if err != nil {
+			tb.Skipf("port %d is taken: %v", spec.Port, err)
+		}
+		tb.Cleanup(func() { ln.Close() })
+	}
+}
+
+// checkPortSequentially is what the CLI did per port before CheckPorts: a
+// bind check, then lsof and four ps processes for an occupied port
+func checkPortSequentially(spec PortSpec) error {
+	if tryBind("", spec) == nil {
+		return nil
+	}
+	port := strconv.Itoa(spec.Port)
+	output, err := exec.Command("lsof", "-i", ":"+port, "-P").Output()
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 || len(strings.Fields(lines[1])) < 2 {
+		return fmt.Errorf("no lsof output for port %s", port)
+	}
+	pid := strings.Fields(lines[1])[1]
+	for _, field := range []string{"user=", "command=", "rss=", "lstart="} {
+		exec.Command("ps", "-p", pid, "-o", field).Output()
+	}
+	return nil
+}
+
+// benchmarkCheckPorts compares one CheckPorts call against the sequential
+// per-port lookups it replaced, over count ports of which the first
+// occupied are held by listeners
+func benchmarkCheckPorts(b *testing.B, count, occupied int) {
+	specs := tcpRange(benchmarkBasePort, count)
+	holdPorts(b, specs[:occupied])
+	ps := NewScanner(Options{})
+
+	// Make sure the benchmark resolves owners rather than timing errors
+	statuses, err := ps.CheckPorts(context.Background(), specs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, status := range statuses[:occupied] {
+		if status.PID != os.Getpid() {
+			b.Fatalf("port %d: owner %d (%s), want this process", status.Port, status.PID, status.Error)
+		}
+	}
+
+	b.Run("CheckPorts", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := ps.CheckPorts(context.Background(), specs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("sequential lsof+ps", func(b *testing.B) {
+		if _, err := exec.LookPath("lsof"); err != nil {
+			b.Skip("lsof is not installed")
+		}
+		for b.Loop() {
+			for _, spec := range specs {
+				if err := checkPortSequentially(spec); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkCheckPorts100(b *testing.B) {
+	benchmarkCheckPorts(b, 100, 100)
+}
+
+func BenchmarkCheckPorts10000(b *testing.B) {
+	benchmarkCheckPorts(b, 10000, 100)
+}
+
+func TestCheckPortsKeepsInputOrder(t *testing.T) {
+	ps := NewScanner(Options{})
+	specs := tcpRange(benchmarkBasePort, 50)
+
+	statuses, err := ps.CheckPorts(context.Background(), specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != len(specs) {
+		t.Fatalf("got %d statuses for %d ports", len(statuses), len(specs))
+	}
+	for i, status := range statuses {
+		if status.Port != specs[i].Port {
+			t.Errorf("statuses[%d] is port %d, want %d (input order)", i, status.Port, specs[i].Port)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Reasons a port could not be bound, see PortStatus.Reason. They are part of
+// the JSON output, so existing values must not change.
+const (
+	ReasonInUse               = "in_use"                // another socket holds the port
+	ReasonTimeWait            = "time_wait"             // only closed connections linger in TIME_WAIT
+	ReasonPermissionDenied    = "permission_denied"     // e.g. a privileged port without root
+	ReasonAddressNotAvailable = "address_not_available" // --bind names an address this host doesn't have
+	ReasonBindFailed          = "bind_failed"           // any other error, see PortStatus.BindError
+)
+
+// bindFailureReason maps the errno behind a failed bind to a Reason constant
+func bindFailureReason(err error) string {
+	switch {
+	case errors.Is(err, syscall.EADDRINUSE):
+		return ReasonInUse
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return ReasonPermissionDenied
+	case errors.Is(err, syscall.EADDRNOTAVAIL):
+		return ReasonAddressNotAvailable
+	}
+	return ReasonBindFailed
+}
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Container runtimes, see ProcessAnalysis.ContainerRuntime
+const (
+	RuntimeDocker     = "docker"
+	RuntimeContainerd = "containerd"
+	RuntimePodman     = "podman"
+	RuntimeCRIO       = "cri-o"
+	RuntimeKubernetes = "kubernetes" // a kubepods cgroup, whatever the runtime below
+)
+
+// The last element of a container's cgroup path is its 64 hex digit ID,
+// either bare (cgroupfs: "/docker/<id>", "/kubepods/besteffort/pod<uid>/<id>")
+// or in a systemd scope ("docker-<id>.scope", "libpod-<id>.scope",
+// "cri-containerd-<id>.scope", "crio-<id>.scope"). crun moves podman's
+// container into a "container" child of its scope.
+var cgroupContainerID = regexp.MustCompile(`(?:^|/)(?:([a-z-]+)-)?([0-9a-f]{64})(?:\.scope)?(?:/container)?$`)
+
+// parseCgroup finds the container ID and runtime in the contents of
+// /proc/<pid>/cgroup. Both are "" for processes outside a container.
+func parseCgroup(data string) (string, string) {
+	for _, line := range strings.Split(data, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		match := cgroupContainerID.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+
+		prefix := match[1]
+		switch {
+		case prefix == "libpod-conmon":
+			// podman's monitor process, not the container itself
+			continue
+		case strings.Contains(path, "kubepods"):
+			return match[2], RuntimeKubernetes
+		case prefix == "docker" || strings.Contains(path, "/docker/"):
+			return match[2], RuntimeDocker
+		case prefix == "libpod" || strings.Contains(path, "libpod"):
+			return match[2], RuntimePodman
+		case prefix == "crio":
+			return match[2], RuntimeCRIO
+		}
+		// cri-containerd-<id>.scope, nerdctl-<id>.scope, or a containerd
+		// namespace such as /default/<id>
+		return match[2], RuntimeContainerd
+	}
+	return "", ""
+}
+
+// separateNamespace reports whether pid is in another namespace of kind
+// ("pid", "mnt") than we are. Namespaces of processes we may not inspect
+// count as the same.
+func separateNamespace(pid int, kind string) bool {
+	theirs, err := os.Readlink(filepath.Join(procDir, strconv.Itoa(pid), "ns", kind))
+	if err != nil {
+		return false
+	}
+	ours, err := os.Readlink(filepath.Join(procDir, "self", "ns", kind))
+	return err == nil && theirs != ours
+}
+
+// detectContainer fills in the container fields of analysis
+func detectContainer(analysis *ProcessAnalysis) {
+	pidDir := filepath.Join(procDir, strconv.Itoa(analysis.PID))
+	if data, err := os.ReadFile(filepath.Join(pidDir, "cgroup")); err == nil {
+		analysis.ContainerID, analysis.ContainerRuntime = parseCgroup(string(data))
+	}
+
+	analysis.SeparatePIDNamespace = separateNamespace(analysis.PID, "pid")
+	analysis.SeparateMountNamespace = separateNamespace(analysis.PID, "mnt")
+	if analysis.SeparateMountNamespace {
+		// The working directory and project files only exist in the
+		// process's own view of the filesystem, which other users'
+		// processes don't let us into
+		if root := filepath.Join(pidDir, "root"); readableDir(root) {
+			analysis.RootDir = root
+		}
+	}
+}
+
+// readableDir reports whether dir can be opened and listed
+func readableDir(dir string) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	return err == nil || err == io.EOF
+}
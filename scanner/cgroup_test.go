@@ -0,0 +1,48 @@
+package scanner
+
+import "testing"
+
+func TestParseCgroup(t *testing.T) {
+	const id = "4f2a9c1be0d7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1"
+
+	tests := []struct {
+		name        string
+		data        string
+		wantID      string
+		wantRuntime string
+	}{
+		{"host, cgroup v2", "0::/user.slice/user-1000.slice/session-2.scope\n", "", ""},
+		{"host, cgroup v1", "12:pids:/user.slice/user-1000.slice\n1:name=systemd:/user.slice/user-1000.slice/session-2.scope\n", "", ""},
+		{"empty", "", "", ""},
+		{"docker, cgroup v1 cgroupfs",
+			"12:pids:/docker/" + id + "\n11:memory:/docker/" + id + "\n1:name=systemd:/docker/" + id + "\n",
+			id, RuntimeDocker},
+		{"docker, cgroup v2 systemd", "0::/system.slice/docker-" + id + ".scope\n", id, RuntimeDocker},
+		{"docker, cgroup v1 systemd", "4:cpu,cpuacct:/system.slice/docker-" + id + ".scope\n", id, RuntimeDocker},
+		{"containerd under kubernetes, cgroup v2",
+			"0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b7e2a1c_6d3f_4e8a_9b1c_2d4f6a8c0e1f.slice/cri-containerd-" + id + ".scope\n",
+			id, RuntimeKubernetes},
+		{"kubernetes, cgroup v1 cgroupfs", "10:memory:/kubepods/burstable/pod0b7e2a1c-6d3f-4e8a-9b1c-2d4f6a8c0e1f/" + id + "\n", id, RuntimeKubernetes},
+		{"kubernetes besteffort", "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod12ab.slice/crio-" + id + ".scope\n", id, RuntimeKubernetes},
+		{"cri-containerd without kubernetes", "0::/system.slice/cri-containerd-" + id + ".scope\n", id, RuntimeContainerd},
+		{"nerdctl", "0::/system.slice/nerdctl-" + id + ".scope\n", id, RuntimeContainerd},
+		{"containerd namespace", "0::/default/" + id + "\n", id, RuntimeContainerd},
+		{"cri-o", "0::/system.slice/crio-" + id + ".scope\n", id, RuntimeCRIO},
+		{"podman rootful", "0::/machine.slice/libpod-" + id + ".scope\n", id, RuntimePodman},
+		{"podman with crun", "0::/machine.slice/libpod-" + id + ".scope/container\n", id, RuntimePodman},
+		{"rootless podman with crun",
+			"0::/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope/container\n",
+			id, RuntimePodman},
+		{"podman's conmon", "0::/machine.slice/libpod-conmon-" + id + ".scope\n", "", ""},
+		{"short id is not a container", "0::/docker/4f2a9c1be0d7\n", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, runtime := parseCgroup(tt.data)
+			if id != tt.wantID || runtime != tt.wantRuntime {
+				t.Errorf("parseCgroup() = %q, %q, want %q, %q", id, runtime, tt.wantID, tt.wantRuntime)
+			}
+		})
+	}
+}
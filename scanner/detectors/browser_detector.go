@@ -0,0 +1,19 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type BrowserDetector struct{}
+
+func (BrowserDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: process name of a desktop browser ("Google Chrome Helper", "firefox")
+	name := strings.ToLower(process.Name)
+	for _, browser := range []string{"firefox", "chrome", "chromium", "safari", "msedge", "brave"} {
+		if strings.Contains(name, browser) {
+			return Match{Technology: "browser", Confidence: confidenceExecutable}
+		}
+	}
+	return Match{}
+}
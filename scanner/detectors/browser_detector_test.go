@@ -0,0 +1,23 @@
+package detectors
+
+import "testing"
+
+func TestBrowserDetector(t *testing.T) {
+	runDetectorCases(t, BrowserDetector{}, []detectorCase{
+		{
+			name:    "chrome helper",
+			process: &Process{Name: "Google Chrome Helper"},
+			want:    Match{Technology: "browser", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "firefox",
+			process: proc("/usr/lib/firefox/firefox"),
+			want:    Match{Technology: "browser", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "not a browser",
+			process: proc("node"),
+			want:    Match{},
+		},
+	})
+}
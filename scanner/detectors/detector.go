@@ -0,0 +1,171 @@
+package detectors
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Process is the part of scanner.ProcessAnalysis the detectors look at.
+// It lives here so the scanner package can import detectors without a cycle.
+type Process struct {
+	Name        string
+	CommandLine string
+	Args        []string
+	WorkingDir  string
+}
+
+// Match is a detector's verdict. Confidence runs from 0 (no match) to 100.
+type Match struct {
+	Technology string
+	Framework  string // e.g. "nextjs", "django"; empty when not recognised
+	Confidence int
+}
+
+// Detector recognises one technology from a process and the files in its
+// project root. files may be nil when no project root was found.
+type Detector interface {
+	Detect(process *Process, files fs.FS) Match
+}
+
+// Confidence levels shared by the detectors
+const (
+	confidenceExecutable = 90 // the process binary itself is the runtime
+	confidenceLauncher   = 80 // a launcher or package manager for the runtime
+	confidenceMarker     = 30 // only a project file points at the runtime
+	markerBonus          = 10 // project file agrees with the process
+)
+
+type Registry struct {
+	detectors []Detector
+}
+
+func NewRegistry(detectors ...Detector) *Registry {
+	return &Registry{detectors: detectors}
+}
+
+func (r *Registry) Register(detector Detector) {
+	r.detectors = append(r.detectors, detector)
+}
+
+// Detect runs every detector and returns the highest-confidence match.
+// Ties go to the detector registered first.
+func (r *Registry) Detect(process *Process, files fs.FS) Match {
+	best := Match{Technology: "unknown"}
+	for _, detector := range r.detectors {
+		match := detector.Detect(process, files)
+		if match.Confidence > best.Confidence {
+			best = match
+		}
+	}
+	return best
+}
+
+var defaultRegistry = NewRegistry(
+	NodeDetector{},
+	PythonDetector{},
+	GoDetector{},
+	JavaDetector{},
+	PostgresDetector{},
+	RedisDetector{},
+	MySQLDetector{},
+	MongoDetector{},
+	RubyDetector{},
+	PHPDetector{},
+	RustDetector{},
+	DotNetDetector{},
+	BrowserDetector{},
+)
+
+// Default returns the registry with every built-in detector
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// executable returns the lower-cased base name of argv[0], falling back to
+// the process name, e.g. "/usr/local/bin/node" -> "node"
+func executable(process *Process) string {
+	name := process.Name
+	if len(process.Args) > 0 && process.Args[0] != "" {
+		name = process.Args[0]
+	}
+	return baseName(name)
+}
+
+// baseName lower-cases the last path element and drops a Windows ".exe"
+func baseName(arg string) string {
+	name := path.Base(strings.ReplaceAll(arg, "\\", "/"))
+	return strings.TrimSuffix(strings.ToLower(name), ".exe")
+}
+
+// hasArg reports whether any argument after argv[0] has one of the given base names
+func hasArg(process *Process, names ...string) bool {
+	for i, arg := range process.Args {
+		if i == 0 {
+			continue
+		}
+		base := baseName(arg)
+		for _, name := range names {
+			if base == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isOneOf(value string, options ...string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFile reports whether any of names exists in the project root
+func hasFile(files fs.FS, names ...string) bool {
+	if files == nil {
+		return false
+	}
+	for _, name := range names {
+		if _, err := fs.Stat(files, name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFileWithSuffix reports whether the project root holds a file ending in suffix
+func hasFileWithSuffix(files fs.FS, suffix string) bool {
+	if files == nil {
+		return false
+	}
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// score turns a process-level confidence into a Match, adding the marker
+// bonus when the project files agree, or falling back to marker-only
+// confidence when the process itself gave no signal
+func score(technology string, processConfidence int, markerFound bool) Match {
+	confidence := processConfidence
+	switch {
+	case confidence > 0 && markerFound:
+		confidence += markerBonus
+	case confidence == 0 && markerFound:
+		confidence = confidenceMarker
+	}
+	if confidence > 100 {
+		confidence = 100
+	}
+	return Match{Technology: technology, Confidence: confidence}
+}
@@ -0,0 +1,117 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// detectorCase is one row of a detector's table test. A nil files means
+// no project root was found.
+type detectorCase struct {
+	name    string
+	process *Process
+	files   fstest.MapFS
+	want    Match
+}
+
+func runDetectorCases(t *testing.T, detector Detector, cases []detectorCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var files fs.FS
+			if tc.files != nil {
+				files = tc.files
+			}
+			if got := detector.Detect(tc.process, files); got != tc.want {
+				t.Errorf("Detect() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// proc builds a process from its argv, named after argv[0] like ps does
+func proc(args ...string) *Process {
+	return &Process{
+		Name:        baseName(args[0]),
+		CommandLine: strings.Join(args, " "),
+		Args:        args,
+	}
+}
+
+// file is a project file with the given contents
+func file(data string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(data)}
+}
+
+func TestRegistryDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		process *Process
+		files   fstest.MapFS
+		want    string
+	}{
+		{
+			name:    "django project is python, not go",
+			process: proc("/usr/bin/python3", "manage.py", "runserver"),
+			files:   fstest.MapFS{"manage.py": file(""), "requirements.txt": file("Django==5.0\n")},
+			want:    "python",
+		},
+		{
+			name:    "django-admin is python, not go",
+			process: proc("/home/dev/.venv/bin/django-admin", "runserver"),
+			want:    "python",
+		},
+		{
+			name:    "mongod is mongodb, not go",
+			process: proc("/usr/bin/mongod", "--config", "/etc/mongod.conf"),
+			want:    "mongodb",
+		},
+		{
+			name:    "go run in a go module",
+			process: proc("go", "run", "."),
+			files:   fstest.MapFS{"go.mod": file("module example.com/api\n")},
+			want:    "go",
+		},
+		{
+			name:    "npm in a project with go.mod and package.json",
+			process: proc("npm", "run", "dev"),
+			files:   fstest.MapFS{"go.mod": file("module example.com/api\n"), "package.json": file("{}")},
+			want:    "node",
+		},
+		{
+			name:    "nothing matches",
+			process: proc("/usr/sbin/sshd", "-D"),
+			want:    "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files fs.FS
+			if tt.files != nil {
+				files = tt.files
+			}
+			if got := Default().Detect(tt.process, files); got.Technology != tt.want {
+				t.Errorf("Detect() technology = %q, want %q", got.Technology, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryTieGoesToFirst(t *testing.T) {
+	registry := NewRegistry(fixedDetector{"first", 50}, fixedDetector{"second", 50})
+	if got := registry.Detect(proc("app"), nil); got.Technology != "first" {
+		t.Errorf("Detect() technology = %q, want %q", got.Technology, "first")
+	}
+}
+
+type fixedDetector struct {
+	technology string
+	confidence int
+}
+
+func (fd fixedDetector) Detect(process *Process, files fs.FS) Match {
+	return Match{Technology: fd.technology, Confidence: fd.confidence}
+}
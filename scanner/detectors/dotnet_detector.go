@@ -0,0 +1,18 @@
+package detectors
+
+import "io/fs"
+
+type DotNetDetector struct{}
+
+func (DotNetDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: the dotnet host
+	confidence := 0
+	if executable(process) == "dotnet" {
+		confidence = confidenceExecutable
+	}
+
+	// Check: project has a .csproj, .fsproj or .sln
+	marker := hasFileWithSuffix(files, ".csproj") || hasFileWithSuffix(files, ".fsproj") ||
+		hasFileWithSuffix(files, ".sln")
+	return score("dotnet", confidence, marker)
+}
@@ -0,0 +1,29 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDotNetDetector(t *testing.T) {
+	runDetectorCases(t, DotNetDetector{}, []detectorCase{
+		{
+			name:    "dotnet run",
+			process: proc("dotnet", "run"),
+			files:   fstest.MapFS{"Api.csproj": file("<Project Sdk=\"Microsoft.NET.Sdk.Web\" />")},
+			want:    Match{Technology: "dotnet", Confidence: confidenceExecutable + markerBonus},
+		},
+		{
+			name:    "solution only",
+			process: proc("/usr/bin/make"),
+			files:   fstest.MapFS{"Shop.sln": file("")},
+			want:    Match{Technology: "dotnet", Confidence: confidenceMarker},
+		},
+		{
+			name:    "not dotnet",
+			process: proc("node"),
+			files:   fstest.MapFS{"package.json": file("{}")},
+			want:    Match{Technology: "dotnet"},
+		},
+	})
+}
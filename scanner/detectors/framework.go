@@ -0,0 +1,139 @@
+package detectors
+
+import (
+	"encoding/json"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// Framework identifiers reported in Match.Framework
+const (
+	FrameworkNext       = "nextjs"
+	FrameworkVite       = "vite"
+	FrameworkDjango     = "django"
+	FrameworkFastAPI    = "fastapi"
+	FrameworkFlask      = "flask"
+	FrameworkRails      = "rails"
+	FrameworkSpringBoot = "spring-boot"
+)
+
+// argFollowedBy reports whether argv holds an argument with base name cmd
+// directly followed by one of subcommands, e.g. "next dev" or "rails s"
+func argFollowedBy(process *Process, cmd string, subcommands ...string) bool {
+	for i, arg := range process.Args {
+		if baseName(arg) != cmd || i+1 >= len(process.Args) {
+			continue
+		}
+		if isOneOf(process.Args[i+1], subcommands...) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageJSON is the part of package.json the node detector reads
+type packageJSON struct {
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Scripts         map[string]string `json:"scripts"`
+}
+
+// readPackageJSON returns the project's package.json, empty when there is none
+func readPackageJSON(files fs.FS) packageJSON {
+	var pkg packageJSON
+	if files == nil {
+		return pkg
+	}
+	if data, err := fs.ReadFile(files, "package.json"); err == nil {
+		json.Unmarshal(data, &pkg)
+	}
+	return pkg
+}
+
+// dependencies returns every dependency and devDependency
+func (pkg packageJSON) dependencies() map[string]bool {
+	deps := make(map[string]bool)
+	for name := range pkg.Dependencies {
+		deps[name] = true
+	}
+	for name := range pkg.DevDependencies {
+		deps[name] = true
+	}
+	return deps
+}
+
+// scriptArgs resolves "npm run dev", "yarn dev" or "npm start" to the words
+// of the package.json script it runs, or nil for any other command line
+func (pkg packageJSON) scriptArgs(process *Process) []string {
+	if !isOneOf(executable(process), "npm", "yarn", "pnpm") || len(process.Args) < 2 {
+		return nil
+	}
+	name := process.Args[1]
+	if isOneOf(name, "run", "run-script") && len(process.Args) > 2 {
+		name = process.Args[2]
+	}
+	script, ok := pkg.Scripts[name]
+	if !ok {
+		return nil
+	}
+	return strings.Fields(script)
+}
+
+var (
+	requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	quotedName      = regexp.MustCompile(`["']([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:[<>=!~;@][^"']*)?["']`)
+	tomlKey         = regexp.MustCompile(`(?m)^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*=`)
+)
+
+// pythonDependencies returns the distribution names the dependency manifests
+// list, normalized as pip does ("Flask_Cors" is "flask-cors"): one per line
+// of requirements.txt, and the quoted requirements and table keys of
+// pyproject.toml, Pipfile and setup.py
+func pythonDependencies(files fs.FS) map[string]bool {
+	deps := make(map[string]bool)
+	if files == nil {
+		return deps
+	}
+	add := func(name string) {
+		name = strings.ToLower(name)
+		deps[strings.NewReplacer("_", "-", ".", "-").Replace(name)] = true
+	}
+
+	if data, err := fs.ReadFile(files, "requirements.txt"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := requirementName.FindStringSubmatch(line); m != nil {
+				add(m[1])
+			}
+		}
+	}
+	for _, name := range []string{"pyproject.toml", "Pipfile", "setup.py"} {
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			continue
+		}
+		for _, m := range quotedName.FindAllStringSubmatch(string(data), -1) {
+			add(m[1])
+		}
+		for _, m := range tomlKey.FindAllStringSubmatch(string(data), -1) {
+			add(m[1])
+		}
+	}
+	return deps
+}
+
+// mentions reports whether any of the named project files contains needle.
+// It is a plain text search, so needle must include enough context to only
+// match the exact dependency, e.g. `gem "rails"` rather than "rails".
+func mentions(files fs.FS, needle string, names ...string) bool {
+	if files == nil {
+		return false
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(files, name)
+		if err == nil && strings.Contains(strings.ToLower(string(data)), needle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestFrameworkNeedsArgvAndManifest(t *testing.T) {
+	fastapiReqs := fstest.MapFS{"requirements.txt": file("fastapi==0.110\nuvicorn[standard]\n")}
+	starletteReqs := fstest.MapFS{"requirements.txt": file("starlette==0.37\nuvicorn\n")}
+	bothDeps := fstest.MapFS{"package.json": file(`{"scripts": {"dev": "next dev", "build": "vite build"}, "dependencies": {"next": "14.1.0"}, "devDependencies": {"vite": "^5.0.0"}}`)}
+	djangoReqs := fstest.MapFS{"requirements.txt": file("Django==5.0\ncelery[redis]>=5\n")}
+	railsGemfile := fstest.MapFS{"Gemfile": file("source 'https://rubygems.org'\ngem 'rails', '~> 7.1'\ngem 'sidekiq'\n")}
+	springPom := fstest.MapFS{"pom.xml": file("<parent><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-parent</artifactId></parent>")}
+
+	tests := []struct {
+		name     string
+		detector Detector
+		process  *Process
+		files    fstest.MapFS
+		want     string
+	}{
+		{"uvicorn serving fastapi", PythonDetector{}, proc("uvicorn", "main:app"), fastapiReqs, FrameworkFastAPI},
+		{"uvicorn serving starlette", PythonDetector{}, proc("uvicorn", "main:app"), starletteReqs, ""},
+		{"uvicorn without a project", PythonDetector{}, proc("uvicorn", "main:app"), nil, ""},
+		{"runserver without django installed", PythonDetector{}, proc("python3", "manage.py", "runserver"), fstest.MapFS{"requirements.txt": file("requests\n")}, ""},
+		{"runserver picks django over flask", PythonDetector{}, proc("python3", "manage.py", "runserver"),
+			fstest.MapFS{"requirements.txt": file("flask\ndjango\n")}, FrameworkDjango},
+		{"gunicorn from the manifest", PythonDetector{}, proc("gunicorn", "app:app"), fastapiReqs, FrameworkFastAPI},
+		{"next dev without next installed", NodeDetector{}, proc("node", "scripts/next", "dev"), fstest.MapFS{"package.json": file(`{"dependencies": {"express": "4"}}`)}, ""},
+		{"next without a project", NodeDetector{}, proc("node", "node_modules/.bin/next", "dev"), nil, ""},
+		{"vite cli picks vite over next", NodeDetector{}, proc("node", "node_modules/vite/bin/vite.js"), bothDeps, FrameworkVite},
+		{"npm run dev resolves the script", NodeDetector{}, proc("npm", "run", "dev"), bothDeps, FrameworkNext},
+		{"npm run build is not a server", NodeDetector{}, proc("npm", "run", "build"), bothDeps, ""},
+		{"vite build is not a server", NodeDetector{}, proc("node", "node_modules/.bin/vite", "build"), bothDeps, ""},
+		{"build script in a next project", NodeDetector{}, proc("node", "scripts/build.js"), bothDeps, ""},
+		{"celery worker in a django project", PythonDetector{}, proc("celery", "-A", "proj", "worker"), djangoReqs, ""},
+		{"manage.py shell", PythonDetector{}, proc("python3", "manage.py", "shell"), djangoReqs, ""},
+		{"gunicorn serving django", PythonDetector{}, proc("gunicorn", "mysite.wsgi"), djangoReqs, FrameworkDjango},
+		{"flask-cors is not flask", PythonDetector{}, proc("flask", "run"), fstest.MapFS{"requirements.txt": file("flask-cors\n")}, ""},
+		{"flask in pyproject", PythonDetector{}, proc("python", "-m", "flask", "run"),
+			fstest.MapFS{"pyproject.toml": file("[project]\ndescription = \"Flask demo\"\ndependencies = [\"Flask>=3.0\"]\n")}, FrameworkFlask},
+		{"fastapi from poetry", PythonDetector{}, proc("python3", "-m", "uvicorn", "main:app"),
+			fstest.MapFS{"pyproject.toml": file("[tool.poetry.dependencies]\npython = \"^3.12\"\nfastapi = \"^0.110\"\n")}, FrameworkFastAPI},
+		{"rails server", RubyDetector{}, proc("bin/rails", "server"), railsGemfile, FrameworkRails},
+		{"rails console", RubyDetector{}, proc("bin/rails", "console"), railsGemfile, ""},
+		{"sidekiq in a rails project", RubyDetector{}, proc("bundle", "exec", "sidekiq"), railsGemfile, ""},
+		{"rake in a rails project", RubyDetector{}, proc("rake", "db:migrate"), railsGemfile, ""},
+		{"puma in a rails project", RubyDetector{}, proc("bundle", "exec", "puma", "-C", "config/puma.rb"), railsGemfile, FrameworkRails},
+		{"rails-html-sanitizer is not rails", RubyDetector{}, proc("bin/rails", "s"), fstest.MapFS{"Gemfile": file(`gem "rails-html-sanitizer"`)}, ""},
+		{"mvn test in a spring boot project", JavaDetector{}, proc("mvn", "test"), springPom, ""},
+		{"spring boot launcher", JavaDetector{}, proc("java", "-cp", "app.jar", "org.springframework.boot.loader.launch.JarLauncher"), springPom, FrameworkSpringBoot},
+		{"rails s without a gemfile", RubyDetector{}, proc("bin/rails", "s"), nil, ""},
+		{"spring boot gradle plugin", JavaDetector{}, proc("./gradlew", "bootRun"),
+			fstest.MapFS{"build.gradle.kts": file(`plugins { id("org.springframework.boot") version "3.2.0" }`)}, FrameworkSpringBoot},
+		{"spring-boot:run without spring boot", JavaDetector{}, proc("mvn", "spring-boot:run"), fstest.MapFS{"pom.xml": file("<project/>")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var match Match
+			if tt.files == nil {
+				match = tt.detector.Detect(tt.process, nil)
+			} else {
+				match = tt.detector.Detect(tt.process, tt.files)
+			}
+			if match.Framework != tt.want {
+				t.Errorf("Detect() framework = %q, want %q", match.Framework, tt.want)
+			}
+		})
+	}
+}
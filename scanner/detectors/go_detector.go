@@ -0,0 +1,45 @@
+package detectors
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+type GoDetector struct{}
+
+func (GoDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: "go run" or a binary built by it into the go-build cache.
+	// Matching whole names keeps "django" and "mongod" out.
+	confidence := 0
+	exe := executable(process)
+	switch {
+	case exe == "go" || exe == "air":
+		confidence = confidenceExecutable
+	case len(process.Args) > 0 && strings.Contains(process.Args[0], "go-build"):
+		confidence = confidenceExecutable
+	case exe != "" && exe == goModuleBinary(files):
+		// Compiled binaries are named after the last module path element
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has go.mod
+	return score("go", confidence, hasFile(files, "go.mod"))
+}
+
+// goModuleBinary returns the default binary name for the module in go.mod
+func goModuleBinary(files fs.FS) string {
+	if files == nil {
+		return ""
+	}
+	data, err := fs.ReadFile(files, "go.mod")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.ToLower(path.Base(strings.Trim(strings.TrimSpace(module), `"`)))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestGoDetector(t *testing.T) {
+	goMod := fstest.MapFS{"go.mod": file("module github.com/acme/api-server\n\ngo 1.22\n")}
+
+	runDetectorCases(t, GoDetector{}, []detectorCase{
+		{
+			name:    "go run",
+			process: proc("/usr/local/go/bin/go", "run", "./cmd/server"),
+			want:    Match{Technology: "go", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "go run in a module",
+			process: proc("go", "run", "."),
+			files:   goMod,
+			want:    Match{Technology: "go", Confidence: confidenceExecutable + markerBonus},
+		},
+		{
+			name:    "binary in the go-build cache",
+			process: proc("/tmp/go-build2746381/b001/exe/main"),
+			want:    Match{Technology: "go", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "air live reload",
+			process: proc("air"),
+			want:    Match{Technology: "go", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "binary named after the module",
+			process: proc("./api-server", "--port", "8080"),
+			files:   goMod,
+			want:    Match{Technology: "go", Confidence: confidenceLauncher + markerBonus},
+		},
+		{
+			name:    "go.mod only",
+			process: proc("/usr/bin/make", "run"),
+			files:   goMod,
+			want:    Match{Technology: "go", Confidence: confidenceMarker},
+		},
+		{
+			name:    "django is not go",
+			process: proc("/usr/bin/django-admin", "runserver"),
+			files:   fstest.MapFS{"manage.py": file("")},
+			want:    Match{Technology: "go"},
+		},
+		{
+			name:    "mongod is not go",
+			process: proc("/usr/bin/mongod"),
+			want:    Match{Technology: "go"},
+		},
+		{
+			name:    "cargo is not go",
+			process: proc("cargo", "run"),
+			want:    Match{Technology: "go"},
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type JavaDetector struct{}
+
+func (JavaDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: JVM or one of the build tools that launch it
+	confidence := 0
+	switch exe := executable(process); {
+	case isOneOf(exe, "java", "javaw"):
+		confidence = confidenceExecutable
+	case isOneOf(exe, "mvn", "mvnw", "gradle", "gradlew"):
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has pom.xml or a Gradle build
+	marker := hasFile(files, "pom.xml", "build.gradle", "build.gradle.kts")
+	match := score("java", confidence, marker)
+	if confidence > 0 {
+		match.Framework = javaFramework(process, files)
+	}
+	return match
+}
+
+// javaFramework reports Spring Boot for "mvn spring-boot:run", "gradle
+// bootRun" or a JVM started through Spring Boot's launcher, when the build
+// pulls in Spring Boot (its group ID or the Gradle plugin). Other builds,
+// tests and plain jars get no framework.
+func javaFramework(process *Process, files fs.FS) string {
+	server := hasArg(process, "spring-boot:run", "bootrun")
+	for _, arg := range process.Args {
+		if strings.HasPrefix(arg, "org.springframework.boot.loader.") {
+			server = true
+		}
+	}
+	if !server {
+		return ""
+	}
+
+	springBoot := mentions(files, "<groupid>org.springframework.boot</groupid>", "pom.xml") ||
+		mentions(files, `"org.springframework.boot`, "build.gradle", "build.gradle.kts") ||
+		mentions(files, "'org.springframework.boot", "build.gradle", "build.gradle.kts")
+	if springBoot {
+		return FrameworkSpringBoot
+	}
+	return ""
+}
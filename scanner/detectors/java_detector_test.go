@@ -0,0 +1,33 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestJavaDetector(t *testing.T) {
+	runDetectorCases(t, JavaDetector{}, []detectorCase{
+		{
+			name:    "jar",
+			process: proc("/usr/lib/jvm/java-21/bin/java", "-jar", "app.jar"),
+			want:    Match{Technology: "java", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "spring boot via maven",
+			process: proc("./mvnw", "spring-boot:run"),
+			files:   fstest.MapFS{"pom.xml": file("<dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-web</artifactId></dependency>")},
+			want:    Match{Technology: "java", Framework: FrameworkSpringBoot, Confidence: confidenceLauncher + markerBonus},
+		},
+		{
+			name:    "gradle build only",
+			process: proc("/usr/bin/make"),
+			files:   fstest.MapFS{"build.gradle.kts": file("")},
+			want:    Match{Technology: "java", Confidence: confidenceMarker},
+		},
+		{
+			name:    "javascript is not java",
+			process: proc("node", "main.js"),
+			want:    Match{Technology: "java"},
+		},
+	})
+}
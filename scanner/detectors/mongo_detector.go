@@ -0,0 +1,17 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type MongoDetector struct{}
+
+func (MongoDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: mongod or the mongos router
+	exe := executable(process)
+	if isOneOf(exe, "mongod", "mongos") || isOneOf(strings.ToLower(process.Name), "mongod", "mongos") {
+		return Match{Technology: "mongodb", Confidence: confidenceExecutable}
+	}
+	return Match{}
+}
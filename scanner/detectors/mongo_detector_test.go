@@ -0,0 +1,28 @@
+package detectors
+
+import "testing"
+
+func TestMongoDetector(t *testing.T) {
+	runDetectorCases(t, MongoDetector{}, []detectorCase{
+		{
+			name:    "mongod",
+			process: proc("/usr/bin/mongod", "--config", "/etc/mongod.conf"),
+			want:    Match{Technology: "mongodb", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "mongos router",
+			process: proc("mongos"),
+			want:    Match{Technology: "mongodb", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "mongosh client",
+			process: proc("mongosh"),
+			want:    Match{},
+		},
+		{
+			name:    "go is not mongo",
+			process: proc("go", "run", "."),
+			want:    Match{},
+		},
+	})
+}
@@ -0,0 +1,18 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type MySQLDetector struct{}
+
+func (MySQLDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: mysqld or the MariaDB server
+	exe := executable(process)
+	if isOneOf(exe, "mysqld", "mysqld_safe", "mariadbd") ||
+		isOneOf(strings.ToLower(process.Name), "mysqld", "mariadbd") {
+		return Match{Technology: "mysql", Confidence: confidenceExecutable}
+	}
+	return Match{}
+}
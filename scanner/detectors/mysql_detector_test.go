@@ -0,0 +1,23 @@
+package detectors
+
+import "testing"
+
+func TestMySQLDetector(t *testing.T) {
+	runDetectorCases(t, MySQLDetector{}, []detectorCase{
+		{
+			name:    "mysqld",
+			process: proc("/usr/sbin/mysqld"),
+			want:    Match{Technology: "mysql", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "mariadb",
+			process: proc("/usr/sbin/mariadbd", "--user=mysql"),
+			want:    Match{Technology: "mysql", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "mysql client",
+			process: proc("mysql", "-u", "root"),
+			want:    Match{},
+		},
+	})
+}
@@ -1,25 +1,66 @@
-// scanner/detectors/
-// node_detector.go
-//func detectNode(process *ProcessAnalysis) bool {
-// Check: command contains "node", "npm", "npx"
-// Check: working directory has package.json
-// Check: process name is "node"
-//	return strings.Contains(process.CommandLine, "node") ||
-//		strings.Contains(process.CommandLine, "npm") ||
-//		strings.Contains(process.CommandLine, "npx")
-//}
+package detectors
 
-// python_detector.go
-//func detectPython(process *ProcessAnalysis) bool {
-// Check: command contains "python", "pip", "streamlit"
-// Check: working directory has requirements.txt, .py files
-//return strings.Contains(process.CommandLine, "python") ||
-//	strings.Contains(process.CommandLine, "streamlit") ||
-//	strings.Contains(process.CommandLine, "fastapi")
-//}
+import (
+	"io/fs"
+	"strings"
+)
 
-// postgres_detector.go
-//func detectPostgres(process *ProcessAnalysis) bool {
-//	return strings.Contains(process.Name, "postgres") ||
-//		strings.Contains(process.CommandLine, "postgres")
-//}
\ No newline at end of file
+type NodeDetector struct{}
+
+func (NodeDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: process is node itself or one of its package managers
+	confidence := 0
+	switch exe := executable(process); {
+	case isOneOf(exe, "node", "nodejs"):
+		confidence = confidenceExecutable
+	case isOneOf(exe, "npm", "npx", "yarn", "pnpm"):
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has package.json
+	match := score("node", confidence, hasFile(files, "package.json"))
+	if confidence > 0 {
+		match.Framework = nodeFramework(process, files)
+	}
+	return match
+}
+
+// nodeFramework names the framework whose dev or production server argv
+// runs ("next dev", "node_modules/vite/bin/vite.js", or "npm run dev" for a
+// script that does), provided package.json depends on it: a command line
+// alone can't tell a framework's CLI from a namesake script
+func nodeFramework(process *Process, files fs.FS) string {
+	pkg := readPackageJSON(files)
+	deps := pkg.dependencies()
+
+	candidates := []*Process{process}
+	if args := pkg.scriptArgs(process); args != nil {
+		candidates = append(candidates, &Process{Name: process.Name, Args: append([]string{process.Args[0]}, args...)})
+	}
+	for _, candidate := range candidates {
+		switch {
+		case deps["next"] && (argFollowedBy(candidate, "next", "dev", "start") ||
+			strings.HasPrefix(strings.ToLower(candidate.Name), "next-server")):
+			return FrameworkNext
+		case deps["vite"] && viteServer(candidate):
+			return FrameworkVite
+		}
+	}
+	return ""
+}
+
+// viteServer reports whether argv runs the vite CLI's dev or preview
+// server: "vite", "vite dev", "vite --port 5173", but not "vite build"
+func viteServer(process *Process) bool {
+	for i, arg := range process.Args {
+		if i == 0 || !isOneOf(baseName(arg), "vite", "vite.js") {
+			continue
+		}
+		if i+1 == len(process.Args) {
+			return true
+		}
+		next := process.Args[i+1]
+		return strings.HasPrefix(next, "-") || isOneOf(next, "dev", "serve", "preview")
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestNodeDetector(t *testing.T) {
+	runDetectorCases(t, NodeDetector{}, []detectorCase{
+		{
+			name:    "node",
+			process: proc("/usr/local/bin/node", "server.js"),
+			want:    Match{Technology: "node", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "npm with vite in devDependencies",
+			process: proc("npm", "run", "dev"),
+			files:   fstest.MapFS{"package.json": file(`{"scripts": {"dev": "vite --port 5173"}, "devDependencies": {"vite": "^5.0.0"}}`)},
+			want:    Match{Technology: "node", Framework: FrameworkVite, Confidence: confidenceLauncher + markerBonus},
+		},
+		{
+			name:    "windows node.exe",
+			process: proc(`C:\Program Files\nodejs\node.exe`, "index.js"),
+			want:    Match{Technology: "node", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "package.json only",
+			process: proc("/usr/bin/make", "dev"),
+			files:   fstest.MapFS{"package.json": file("{}")},
+			want:    Match{Technology: "node", Confidence: confidenceMarker},
+		},
+		{
+			name:    "not node",
+			process: proc("python3", "app.py"),
+			want:    Match{Technology: "node"},
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type PHPDetector struct{}
+
+func (PHPDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: php CLI (php, php8.2) or php-fpm (php-fpm8.2)
+	confidence := 0
+	exe := executable(process)
+	switch {
+	case strings.HasPrefix(exe, "php"):
+		confidence = confidenceExecutable
+	case strings.HasPrefix(strings.ToLower(process.Name), "php-fpm"):
+		confidence = confidenceExecutable
+	case exe == "composer":
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has composer.json
+	return score("php", confidence, hasFile(files, "composer.json"))
+}
@@ -0,0 +1,32 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestPHPDetector(t *testing.T) {
+	runDetectorCases(t, PHPDetector{}, []detectorCase{
+		{
+			name:    "built-in server",
+			process: proc("php", "-S", "localhost:8000"),
+			files:   fstest.MapFS{"composer.json": file("{}")},
+			want:    Match{Technology: "php", Confidence: confidenceExecutable + markerBonus},
+		},
+		{
+			name:    "php-fpm master",
+			process: &Process{Name: "php-fpm8.2", Args: []string{"php-fpm: master process (/etc/php/8.2/fpm/php-fpm.conf)"}},
+			want:    Match{Technology: "php", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "composer",
+			process: proc("composer", "install"),
+			want:    Match{Technology: "php", Confidence: confidenceLauncher},
+		},
+		{
+			name:    "not php",
+			process: proc("python3"),
+			want:    Match{Technology: "php"},
+		},
+	})
+}
@@ -0,0 +1,18 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type PostgresDetector struct{}
+
+func (PostgresDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: server binary, or a backend whose title was rewritten to "postgres: ..."
+	exe := executable(process)
+	if isOneOf(exe, "postgres", "postmaster", "postgres:") ||
+		strings.HasPrefix(strings.ToLower(process.Name), "postgres") {
+		return Match{Technology: "postgres", Confidence: confidenceExecutable}
+	}
+	return Match{}
+}
@@ -0,0 +1,23 @@
+package detectors
+
+import "testing"
+
+func TestPostgresDetector(t *testing.T) {
+	runDetectorCases(t, PostgresDetector{}, []detectorCase{
+		{
+			name:    "server binary",
+			process: proc("/usr/lib/postgresql/16/bin/postgres", "-D", "/var/lib/postgresql/16/main"),
+			want:    Match{Technology: "postgres", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "rewritten backend title",
+			process: &Process{Name: "postgres", Args: []string{"postgres:", "checkpointer"}},
+			want:    Match{Technology: "postgres", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "psql client",
+			process: proc("psql"),
+			want:    Match{},
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type PythonDetector struct{}
+
+func (PythonDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: interpreter (python, python3, python3.12, pythonw) or a Python server entry point
+	confidence := 0
+	exe := executable(process)
+	switch {
+	case strings.HasPrefix(exe, "python"):
+		confidence = confidenceExecutable
+	case isOneOf(exe, "uvicorn", "gunicorn", "hypercorn", "daphne", "streamlit", "flask", "django-admin", "celery", "jupyter", "pip", "poetry", "uv"):
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has requirements.txt, pyproject.toml and friends
+	marker := hasFile(files, "requirements.txt", "pyproject.toml", "setup.py", "Pipfile", "manage.py")
+	match := score("python", confidence, marker)
+	if confidence > 0 {
+		match.Framework = pythonFramework(process, files)
+	}
+	return match
+}
+
+// Python servers that take an app to serve from the command line
+var (
+	asgiServers = []string{"uvicorn", "hypercorn", "daphne"}
+	wsgiServers = []string{"gunicorn", "waitress-serve", "uwsgi"}
+)
+
+// pythonFramework names the framework argv serves, confirmed by the
+// dependency manifests: "manage.py runserver" is Django, "flask run" is
+// Flask, and an ASGI or WSGI server serves whichever of FastAPI, Django or
+// Flask the project depends on. Workers, shells and scripts ("celery
+// worker", "manage.py shell", "python build.py") get no framework.
+func pythonFramework(process *Process, files fs.FS) string {
+	deps := pythonDependencies(files)
+	exe := executable(process)
+	server := func(names ...string) bool { return isOneOf(exe, names...) || hasArg(process, names...) }
+
+	switch {
+	case deps["django"] && (hasArg(process, "manage.py", "django-admin") || exe == "django-admin") && hasArg(process, "runserver"):
+		return FrameworkDjango
+	case deps["flask"] && (argFollowedBy(process, "flask", "run") || (exe == "flask" && hasArg(process, "run"))):
+		return FrameworkFlask
+	case server(asgiServers...):
+		switch {
+		case deps["fastapi"]:
+			return FrameworkFastAPI
+		case deps["django"]:
+			return FrameworkDjango
+		}
+	case server(wsgiServers...):
+		switch {
+		case deps["django"]:
+			return FrameworkDjango
+		case deps["flask"]:
+			return FrameworkFlask
+		case deps["fastapi"]: // gunicorn with uvicorn workers
+			return FrameworkFastAPI
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestPythonDetector(t *testing.T) {
+	runDetectorCases(t, PythonDetector{}, []detectorCase{
+		{
+			name:    "versioned interpreter",
+			process: proc("/usr/bin/python3.12", "app.py"),
+			want:    Match{Technology: "python", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "django project",
+			process: proc("python3", "manage.py", "runserver"),
+			files:   fstest.MapFS{"manage.py": file(""), "requirements.txt": file("Django==5.0\n")},
+			want:    Match{Technology: "python", Framework: FrameworkDjango, Confidence: confidenceExecutable + markerBonus},
+		},
+		{
+			name:    "gunicorn with flask in requirements",
+			process: proc("/srv/.venv/bin/gunicorn", "app:app"),
+			files:   fstest.MapFS{"requirements.txt": file("flask==3.0\ngunicorn\n")},
+			want:    Match{Technology: "python", Framework: FrameworkFlask, Confidence: confidenceLauncher + markerBonus},
+		},
+		{
+			name:    "pyproject only",
+			process: proc("/usr/bin/make", "serve"),
+			files:   fstest.MapFS{"pyproject.toml": file("[project]\nname = \"api\"\n")},
+			want:    Match{Technology: "python", Confidence: confidenceMarker},
+		},
+		{
+			name:    "not python",
+			process: proc("node", "server.js"),
+			want:    Match{Technology: "python"},
+		},
+	})
+}
@@ -0,0 +1,19 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type RedisDetector struct{}
+
+func (RedisDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: redis-server or redis-sentinel (the title looks like "redis-server *:6379")
+	exe := executable(process)
+	name := strings.ToLower(process.Name)
+	if isOneOf(exe, "redis-server", "redis-sentinel") ||
+		strings.HasPrefix(name, "redis-server") || strings.HasPrefix(name, "redis-sentinel") {
+		return Match{Technology: "redis", Confidence: confidenceExecutable}
+	}
+	return Match{}
+}
@@ -0,0 +1,23 @@
+package detectors
+
+import "testing"
+
+func TestRedisDetector(t *testing.T) {
+	runDetectorCases(t, RedisDetector{}, []detectorCase{
+		{
+			name:    "redis-server title",
+			process: &Process{Name: "redis-server", Args: []string{"redis-server *:6379"}},
+			want:    Match{Technology: "redis", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "sentinel",
+			process: proc("/usr/bin/redis-sentinel", "/etc/redis/sentinel.conf"),
+			want:    Match{Technology: "redis", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "redis-cli",
+			process: proc("redis-cli", "ping"),
+			want:    Match{},
+		},
+	})
+}
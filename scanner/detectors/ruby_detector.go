@@ -0,0 +1,44 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type RubyDetector struct{}
+
+func (RubyDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: interpreter (ruby, ruby3.2) or a Rack server/runner
+	confidence := 0
+	exe := executable(process)
+	switch {
+	case strings.HasPrefix(exe, "ruby"):
+		confidence = confidenceExecutable
+	case isOneOf(exe, "rails", "bundle", "rackup", "puma", "unicorn", "sidekiq", "rake"):
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has a Gemfile
+	match := score("ruby", confidence, hasFile(files, "Gemfile"))
+	if confidence > 0 {
+		match.Framework = rubyFramework(process, files)
+	}
+	return match
+}
+
+// rubyFramework reports Rails for "rails s"/"rails server" or a Rack server
+// (puma, unicorn, rackup) in a project whose Gemfile pulls in rails.
+// Sidekiq, rake and "rails console" get no framework.
+func rubyFramework(process *Process, files fs.FS) string {
+	server := argFollowedBy(process, "rails", "s", "server") ||
+		isOneOf(executable(process), "puma", "unicorn", "rackup") ||
+		hasArg(process, "puma", "unicorn", "rackup") ||
+		strings.HasPrefix(strings.ToLower(process.Name), "puma") // "puma 6.4.0 (tcp://0.0.0.0:3000)"
+	if !server {
+		return ""
+	}
+	if mentions(files, `gem "rails"`, "Gemfile") || mentions(files, "gem 'rails'", "Gemfile") {
+		return FrameworkRails
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestRubyDetector(t *testing.T) {
+	runDetectorCases(t, RubyDetector{}, []detectorCase{
+		{
+			name:    "rails server",
+			process: proc("bin/rails", "server"),
+			files:   fstest.MapFS{"Gemfile": file("gem \"rails\", \"~> 7.1\"\n")},
+			want:    Match{Technology: "ruby", Framework: FrameworkRails, Confidence: confidenceLauncher + markerBonus},
+		},
+		{
+			name:    "versioned interpreter",
+			process: proc("/usr/bin/ruby3.2", "app.rb"),
+			want:    Match{Technology: "ruby", Confidence: confidenceExecutable},
+		},
+		{
+			name:    "puma",
+			process: proc("puma"),
+			want:    Match{Technology: "ruby", Confidence: confidenceLauncher},
+		},
+		{
+			name:    "not ruby",
+			process: proc("rustc"),
+			want:    Match{Technology: "ruby"},
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package detectors
+
+import (
+	"io/fs"
+	"strings"
+)
+
+type RustDetector struct{}
+
+func (RustDetector) Detect(process *Process, files fs.FS) Match {
+	// Check: "cargo run" or a binary under target/debug or target/release
+	confidence := 0
+	switch {
+	case executable(process) == "cargo":
+		confidence = confidenceExecutable
+	case len(process.Args) > 0 && isCargoTarget(process.Args[0]):
+		confidence = confidenceLauncher
+	}
+
+	// Check: project has Cargo.toml
+	return score("rust", confidence, hasFile(files, "Cargo.toml"))
+}
+
+func isCargoTarget(path string) bool {
+	path = strings.ReplaceAll(path, "\\", "/")
+	return strings.Contains(path, "/target/debug/") || strings.Contains(path, "/target/release/")
+}
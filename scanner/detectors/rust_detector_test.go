@@ -0,0 +1,27 @@
+package detectors
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestRustDetector(t *testing.T) {
+	runDetectorCases(t, RustDetector{}, []detectorCase{
+		{
+			name:    "cargo run",
+			process: proc("/home/dev/.cargo/bin/cargo", "run"),
+			files:   fstest.MapFS{"Cargo.toml": file("[package]\nname = \"api\"\n")},
+			want:    Match{Technology: "rust", Confidence: confidenceExecutable + markerBonus},
+		},
+		{
+			name:    "release binary",
+			process: proc("/home/dev/api/target/release/api"),
+			want:    Match{Technology: "rust", Confidence: confidenceLauncher},
+		},
+		{
+			name:    "not rust",
+			process: proc("/usr/bin/target"),
+			want:    Match{Technology: "rust"},
+		},
+	})
+}
@@ -0,0 +1,209 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Container is the Docker container publishing a port. The process holding
+// the port is then only docker-proxy, or com.docker.backend on macOS.
+type Container struct {
+	ID             string // short, 12 characters
+	Name           string
+	Image          string
+	ComposeProject string // "" when not started by docker compose
+	ComposeService string
+	HostIP         string // address the port is published on, "" for every interface
+	TargetPort     int    // port inside the container
+}
+
+// Labels docker compose sets on the containers it creates
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// dockerTimeout bounds every Engine API request, so a wedged daemon can't
+// stall the scan
+const dockerTimeout = 2 * time.Second
+
+// DockerClient talks to the Docker Engine API over its unix socket
+type DockerClient struct {
+	client *http.Client
+}
+
+func NewDockerClient(socketPath string) *DockerClient {
+	dialer := &net.Dialer{Timeout: dockerTimeout}
+	return &DockerClient{
+		client: &http.Client{
+			Timeout: dockerTimeout,
+			Transport: &http.Transport{
+				// The host part of request URLs is ignored
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// DockerSocketPath returns the Engine API socket: DOCKER_HOST when it is a
+// unix:// URL, otherwise the first standard location that exists (Linux,
+// Docker Desktop, Colima, rootless). It returns "" when there is none.
+func DockerSocketPath() string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		// tcp:// and ssh:// daemons publish ports on another host
+		path, ok := strings.CutPrefix(host, "unix://")
+		if !ok {
+			return ""
+		}
+		return path
+	}
+
+	candidates := []string{"/var/run/docker.sock"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".docker", "run", "docker.sock"),
+			filepath.Join(home, ".colima", "default", "docker.sock"))
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "docker.sock"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// dockerContainer is the part of a GET /containers/json entry we read
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+}
+
+// PublishedPorts maps the host ports of running containers to the
+// containers publishing them. A port published on both 0.0.0.0 and :: is
+// listed once per address.
+func (dc *DockerClient) PublishedPorts(ctx context.Context) (map[PortSpec][]Container, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("docker engine API: %s", resp.Status)
+	}
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("docker engine API: %w", err)
+	}
+
+	published := make(map[PortSpec][]Container)
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		for _, port := range c.Ports {
+			if port.PublicPort == 0 || (port.Type != ProtocolTCP && port.Type != ProtocolUDP) {
+				continue
+			}
+			hostIP := port.IP
+			if ip := net.ParseIP(hostIP); ip != nil && ip.IsUnspecified() {
+				hostIP = ""
+			}
+			spec := PortSpec{Port: port.PublicPort, Protocol: port.Type}
+			published[spec] = append(published[spec], Container{
+				ID:             c.ID[:min(12, len(c.ID))],
+				Name:           name,
+				Image:          c.Image,
+				ComposeProject: c.Labels[composeProjectLabel],
+				ComposeService: c.Labels[composeServiceLabel],
+				HostIP:         hostIP,
+				TargetPort:     port.PrivatePort,
+			})
+		}
+	}
+	return published, nil
+}
+
+// dockerForwarders hold published ports on behalf of containers: the
+// userland proxy, Docker Desktop's backend and rootless docker's port driver
+var dockerForwarders = []string{"docker-proxy", "com.docker.backend", "com.docker.vpnkit", "vpnkit", "rootlessport", "rootlesskit"}
+
+// lsofCommandWidth is where lsof cuts off the COMMAND column
+const lsofCommandWidth = 9
+
+func isDockerForwarder(name string) bool {
+	for _, forwarder := range dockerForwarders {
+		if name == forwarder || (len(name) == lsofCommandWidth && strings.HasPrefix(forwarder, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayBePublished reports whether a container could be behind status's
+// port: it is held by a docker forwarder, or by a process we can't see
+func mayBePublished(status *PortStatus) bool {
+	return status.Reason == ReasonInUse && (status.PID == 0 || isDockerForwarder(status.ProcessName))
+}
+
+// attachContainers sets Container on the occupied ports a running container
+// publishes. Docker not running, or its socket not being readable by us,
+// isn't an error: those ports keep showing the proxy process.
+func attachContainers(ctx context.Context, statuses []*PortStatus, socketPath string) {
+	candidates := false
+	for _, status := range statuses {
+		candidates = candidates || mayBePublished(status)
+	}
+	if !candidates || socketPath == "" {
+		return
+	}
+
+	published, err := NewDockerClient(socketPath).PublishedPorts(ctx)
+	if err != nil {
+		return
+	}
+	for _, status := range statuses {
+		if mayBePublished(status) {
+			status.Container = matchContainer(published[status.Spec()], status.Addresses)
+		}
+	}
+}
+
+// matchContainer returns the container published on an address the port is
+// bound on, nil when none is: another process holds the port there
+func matchContainer(containers []Container, addresses []string) *Container {
+	for i, container := range containers {
+		for _, addr := range addresses {
+			ip := net.ParseIP(addr)
+			if container.HostIP == addr || (container.HostIP == "" && ip != nil && ip.IsUnspecified()) {
+				return &containers[i]
+			}
+		}
+	}
+	return nil
+}
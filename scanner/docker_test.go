@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeEngine serves handler on a unix socket like the Docker daemon and
+// returns the socket path
+func fakeEngine(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return socketPath
+}
+
+// containersJSON is a GET /containers/json response: a compose service
+// published on both wildcards, a database on loopback only, and ports the
+// client must skip
+const containersJSON = `[
+  {
+    "Id": "4f2a9c1be0d7e1a3b5c7d9f0a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8",
+    "Names": ["/shop-web-1"],
+    "Image": "nginx:1.25",
+    "Labels": {"com.docker.compose.project": "shop", "com.docker.compose.service": "web"},
+    "Ports": [
+      {"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+      {"IP": "::", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+      {"PrivatePort": 443, "Type": "tcp"}
+    ]
+  },
+  {
+    "Id": "99aa0c3d5e7f",
+    "Names": ["/pg"],
+    "Image": "postgres:16",
+    "Labels": {},
+    "Ports": [
+      {"IP": "127.0.0.1", "PrivatePort": 5432, "PublicPort": 5432, "Type": "tcp"},
+      {"IP": "0.0.0.0", "PrivatePort": 9000, "PublicPort": 9000, "Type": "sctp"}
+    ]
+  }
+]`
+
+func TestPublishedPorts(t *testing.T) {
+	socketPath := fakeEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(containersJSON))
+	})
+
+	published, err := NewDockerClient(socketPath).PublishedPorts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web := Container{
+		ID: "4f2a9c1be0d7", Name: "shop-web-1", Image: "nginx:1.25",
+		ComposeProject: "shop", ComposeService: "web", TargetPort: 80,
+	}
+	want := map[PortSpec][]Container{
+		// 0.0.0.0 and :: are both every interface, listed once per address
+		{Port: 8080, Protocol: ProtocolTCP}: {web, web},
+		{Port: 5432, Protocol: ProtocolTCP}: {{
+			ID: "99aa0c3d5e7f", Name: "pg", Image: "postgres:16", HostIP: "127.0.0.1", TargetPort: 5432,
+		}},
+	}
+	if !reflect.DeepEqual(published, want) {
+		t.Errorf("PublishedPorts() = %+v, want %+v", published, want)
+	}
+}
+
+func TestPublishedPortsErrors(t *testing.T) {
+	t.Run("socket missing", func(t *testing.T) {
+		client := NewDockerClient(filepath.Join(t.TempDir(), "docker.sock"))
+		if _, err := client.PublishedPorts(context.Background()); err == nil {
+			t.Error("PublishedPorts() succeeded without a daemon")
+		}
+	})
+
+	t.Run("non-200 response", func(t *testing.T) {
+		socketPath := fakeEngine(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"client version 1.99 is too new"}`, http.StatusBadRequest)
+		})
+		_, err := NewDockerClient(socketPath).PublishedPorts(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "400") {
+			t.Errorf("PublishedPorts() error = %v, want the HTTP status", err)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		socketPath := fakeEngine(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html>"))
+		})
+		if _, err := NewDockerClient(socketPath).PublishedPorts(context.Background()); err == nil {
+			t.Error("PublishedPorts() accepted a non-JSON body")
+		}
+	})
+}
+
+func TestAttachContainers(t *testing.T) {
+	socketPath := fakeEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(containersJSON))
+	})
+
+	tests := []struct {
+		name   string
+		status PortStatus
+		want   string // container name, "" for none
+	}{
+		{
+			name:   "docker-proxy on the wildcard",
+			status: PortStatus{Port: 8080, Protocol: ProtocolTCP, Reason: ReasonInUse, PID: 2301, ProcessName: "docker-proxy", Addresses: []string{"0.0.0.0", "::"}},
+			want:   "shop-web-1",
+		},
+		{
+			name:   "owner hidden from us",
+			status: PortStatus{Port: 5432, Protocol: ProtocolTCP, Reason: ReasonInUse, Addresses: []string{"127.0.0.1"}},
+			want:   "pg",
+		},
+		{
+			name:   "docker desktop backend cut off by lsof",
+			status: PortStatus{Port: 8080, Protocol: ProtocolTCP, Reason: ReasonInUse, PID: 611, ProcessName: "com.docke", Addresses: []string{"::"}},
+			want:   "shop-web-1",
+		},
+		{
+			name:   "native postgres on the same port",
+			status: PortStatus{Port: 5432, Protocol: ProtocolTCP, Reason: ReasonInUse, PID: 880, ProcessName: "postgres", Addresses: []string{"127.0.0.1"}},
+		},
+		{
+			name:   "published on another address",
+			status: PortStatus{Port: 5432, Protocol: ProtocolTCP, Reason: ReasonInUse, Addresses: []string{"192.168.1.20"}},
+		},
+		{
+			name:   "free port",
+			status: PortStatus{Port: 8080, Protocol: ProtocolTCP, IsAvailable: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			attachContainers(context.Background(), []*PortStatus{&status}, socketPath)
+
+			got := ""
+			if status.Container != nil {
+				got = status.Container.Name
+			}
+			if got != tt.want {
+				t.Errorf("Container = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachContainersWithoutDaemon(t *testing.T) {
+	status := &PortStatus{Port: 8080, Protocol: ProtocolTCP, Reason: ReasonInUse, ProcessName: "docker-proxy", PID: 2301, Addresses: []string{"0.0.0.0"}}
+	attachContainers(context.Background(), []*PortStatus{status}, filepath.Join(t.TempDir(), "docker.sock"))
+	if status.Container != nil {
+		t.Errorf("Container = %+v without a daemon", status.Container)
+	}
+}
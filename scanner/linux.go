@@ -0,0 +1,298 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LinuxScanner reads socket ownership straight from /proc instead of
+// shelling out to lsof and ps. The socket list itself comes from /proc/net,
+// netlink or ss, see Options.Backend.
+type LinuxScanner struct {
+	bindAddress  string
+	dockerSocket string
+	backend      string
+	snapshot     func() (*socketSnapshot, error)
+}
+
+func (ls *LinuxScanner) Backend() string {
+	return ls.backend
+}
+
+func (ls *LinuxScanner) BindAddress() string {
+	return ls.bindAddress
+}
+
+func (ls *LinuxScanner) CheckPort(port int) (*PortStatus, error) {
+	statuses, err := ls.CheckPorts(context.Background(), []PortSpec{{Port: port, Protocol: ProtocolTCP}})
+	if err != nil {
+		return nil, err
+	}
+	return statuses[0], nil
+}
+
+// CheckPorts bind-checks all ports concurrently, then resolves the owners of
+// the occupied ones from a single /proc snapshot. Results are in input order.
+func (ls *LinuxScanner) CheckPorts(ctx context.Context, specs []PortSpec) ([]*PortStatus, error) {
+	bindErrs, err := checkAvailability(ctx, specs, ls.checkBind)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*PortStatus, len(specs))
+	var snapshot *socketSnapshot
+	var snapshotErr error
+	// A process holding several ports is only read once
+	owners := make(map[int]*ownerDetails)
+	lookup := func(pid int) *ownerDetails {
+		details, ok := owners[pid]
+		if !ok {
+			details = ls.getOwnerDetails(pid)
+			if name := snapshot.processNames[pid]; details == nil && name != "" {
+				// /proc/<pid> is hidden from us, but the backend named the process
+				details = &ownerDetails{name: name, user: "unknown", commandLine: "unknown", memoryUsage: "unknown", startTime: "unknown"}
+			}
+			owners[pid] = details
+		}
+		return details
+	}
+
+	for i, spec := range specs {
+		status := &PortStatus{Port: spec.Port, Protocol: spec.Protocol, IsAvailable: bindErrs[i] == nil}
+		statuses[i] = status
+		if !status.IsAvailable {
+			status.Reason = bindFailureReason(bindErrs[i])
+			status.BindError = bindErrs[i].Error()
+		}
+
+		// Only pay for the snapshot when something is occupied. A port that
+		// is free on --bind may still be bound on another address.
+		if status.IsAvailable && ls.bindAddress == "" {
+			continue
+		}
+		if snapshot == nil && snapshotErr == nil {
+			snapshot, snapshotErr = ls.takeSocketSnapshot()
+		}
+		if snapshotErr != nil {
+			if status.Reason == ReasonInUse {
+				status.Error = snapshotErr.Error()
+				status.ErrorCode = ErrCodeOwnerLookup
+			}
+			continue
+		}
+
+		recordAddresses(status, snapshot.addresses(spec))
+		// A denied bind can still hide a listener
+		if status.Reason == ReasonPermissionDenied && len(status.Addresses) > 0 {
+			status.Reason = ReasonInUse
+		}
+		if status.Reason != ReasonInUse {
+			continue
+		}
+
+		pids, err := snapshot.owners(spec)
+		if err != nil {
+			if snapshot.lingering(spec) {
+				status.Reason = ReasonTimeWait
+				continue
+			}
+			status.Error = err.Error()
+			status.ErrorCode = ErrCodeOwnerLookup
+			continue
+		}
+
+		status.Owners = resolveOwners(pids, lookup)
+		if len(status.Owners) == 0 {
+			status.Error = fmt.Sprintf("process holding %s port %d exited", spec.Protocol, spec.Port)
+			status.ErrorCode = ErrCodeOwnerLookup
+			continue
+		}
+		status.PID = status.Owners[0].PID
+		lookup(status.PID).apply(status)
+	}
+
+	attachContainers(ctx, statuses, ls.dockerSocket)
+	return statuses, nil
+}
+
+// Listening lists the listening ports from one socket snapshot
+func (ls *LinuxScanner) Listening(ctx context.Context) ([]PortSpec, error) {
+	snapshot, err := ls.takeSocketSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[PortSpec]bool)
+	for _, entry := range snapshot.entries {
+		if entry.listening() {
+			seen[PortSpec{Port: entry.LocalPort, Protocol: entry.Protocol}] = true
+		}
+	}
+	return sortSpecs(seen), nil
+}
+
+func (ls *LinuxScanner) checkBind(spec PortSpec) error {
+	return tryBind(ls.bindAddress, spec)
+}
+
+// socketSnapshot is one read of the socket tables plus the inode to PID mapping
+type socketSnapshot struct {
+	entries     []socketEntry
+	inodeOwners map[uint64][]int
+	// Process names reported by the backend itself (ss), for processes
+	// whose /proc entry we can't read
+	processNames map[int]string
+}
+
+func (ls *LinuxScanner) takeSocketSnapshot() (*socketSnapshot, error) {
+	if ls.snapshot == nil { // zero value LinuxScanner
+		return procSnapshot(readSocketTables)()
+	}
+	snapshot, err := ls.snapshot()
+	if err != nil && ls.backend == BackendNetlink {
+		// A table the probe didn't cover failed; /proc/net has them all
+		ls.backend, ls.snapshot = BackendProc, procSnapshot(readSocketTables)
+		return ls.snapshot()
+	}
+	return snapshot, err
+}
+
+// procSnapshot pairs a socket table reader with the inode owners found by
+// walking /proc/<pid>/fd
+func procSnapshot(list func() ([]socketEntry, error)) func() (*socketSnapshot, error) {
+	return func() (*socketSnapshot, error) {
+		entries, err := list()
+		if err != nil {
+			return nil, err
+		}
+		return &socketSnapshot{entries: entries, inodeOwners: socketOwners()}, nil
+	}
+}
+
+// addresses returns the local addresses spec is bound on. For TCP these are
+// the listeners' addresses, unless nothing listens.
+func (ss *socketSnapshot) addresses(spec PortSpec) []net.IP {
+	var listening, all []net.IP
+	for _, entry := range ss.entries {
+		if entry.LocalPort != spec.Port || entry.Protocol != spec.Protocol {
+			continue
+		}
+		all = append(all, entry.LocalIP)
+		if entry.Protocol == ProtocolTCP && entry.State == tcpListen {
+			listening = append(listening, entry.LocalIP)
+		}
+	}
+	if len(listening) > 0 {
+		return listening
+	}
+	return all
+}
+
+// lingering reports whether spec is only held by TCP connections in
+// TIME_WAIT, which belong to no process and go away on their own
+func (ss *socketSnapshot) lingering(spec PortSpec) bool {
+	if spec.Protocol != ProtocolTCP {
+		return false
+	}
+	timeWait := false
+	for _, entry := range ss.entries {
+		if entry.LocalPort != spec.Port || entry.Protocol != spec.Protocol {
+			continue
+		}
+		if entry.State != tcpTimeWait {
+			return false
+		}
+		timeWait = true
+	}
+	return timeWait
+}
+
+// owners finds the PIDs holding spec. For TCP, the processes holding a
+// listening socket win over those with other sockets on the same local port.
+func (ss *socketSnapshot) owners(spec PortSpec) ([]int, error) {
+	var listeners, others []socketEntry
+	for _, entry := range ss.entries {
+		if entry.LocalPort != spec.Port || entry.Protocol != spec.Protocol || entry.Inode == 0 {
+			continue
+		}
+		if entry.Protocol == ProtocolTCP && entry.State == tcpListen {
+			listeners = append(listeners, entry)
+		} else {
+			others = append(others, entry)
+		}
+	}
+
+	if len(listeners) == 0 && len(others) == 0 {
+		return nil, fmt.Errorf("no %s socket found for port %d in the socket table", spec.Protocol, spec.Port)
+	}
+
+	for _, group := range [][]socketEntry{listeners, others} {
+		seen := make(map[int]bool)
+		var pids []int
+		for _, entry := range group {
+			for _, pid := range ss.inodeOwners[entry.Inode] {
+				if !seen[pid] {
+					seen[pid] = true
+					pids = append(pids, pid)
+				}
+			}
+		}
+		if len(pids) > 0 {
+			return pids, nil
+		}
+	}
+
+	uid := append(listeners, others...)[0].UID
+	return nil, fmt.Errorf("%s socket on port %d is owned by uid %d (run as root to see the process)", spec.Protocol, spec.Port, uid)
+}
+
+// getOwnerDetails returns nil when pid no longer exists
+func (ls *LinuxScanner) getOwnerDetails(pid int) *ownerDetails {
+	status, err := readProcStatus(pid)
+	if err != nil {
+		return nil
+	}
+
+	details := &ownerDetails{
+		name:        "unknown",
+		user:        "unknown",
+		commandLine: "unknown",
+		memoryUsage: "unknown",
+		startTime:   "unknown",
+	}
+
+	if name, err := readProcName(pid); err == nil {
+		details.name = name
+	}
+
+	if args, err := readProcArgs(pid); err == nil {
+		if len(args) == 0 {
+			details.commandLine = "[" + details.name + "]"
+		} else {
+			details.commandLine = strings.Join(args, " ")
+		}
+	}
+
+	if ppid, err := strconv.Atoi(status["PPid"]); err == nil {
+		details.ppid = ppid
+	}
+	if uid, err := uidFromStatus(status); err == nil {
+		details.user = lookupUsername(uid)
+	}
+	if kb, err := rssKB(status); err == nil {
+		details.memoryUsage = fmt.Sprintf("%dMB", kb/1024)
+		details.memoryBytes = int64(kb) * 1024
+	}
+
+	if started, err := readProcStartTime(pid); err == nil {
+		// Same layout as ps -o lstart= so both scanners print alike
+		details.startTime = started.Format(time.ANSIC)
+		details.started = started
+	}
+
+	return details
+}
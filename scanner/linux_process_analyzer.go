@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type LinuxProcessAnalyzer struct{}
+
+func NewLinuxProcessAnalyzer() *LinuxProcessAnalyzer {
+	return &LinuxProcessAnalyzer{}
+}
+
+func (lpa *LinuxProcessAnalyzer) AnalyzeProcess(pid int) (*ProcessAnalysis, error) {
+	analysis := &ProcessAnalysis{
+		PID: pid,
+	}
+
+	// Get basic process info
+	name, args, wd, user, err := lpa.getProcessDetails(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis.Name = name
+	analysis.Args = args
+	analysis.CommandLine = strings.Join(args, " ")
+	analysis.WorkingDir = wd
+	analysis.User = user
+
+	// A containerised process's working directory is a path in its own
+	// filesystem, so the project root is looked up through /proc/<pid>/root.
+	// Without access to that root the path would be resolved on the host
+	// instead, so the project is left unknown.
+	detectContainer(analysis)
+	analysis.ConfigFiles = []string{}
+	if !analysis.SeparateMountNamespace || analysis.RootDir != "" {
+		analysis.ProjectPath, analysis.ConfigFiles = findProjectRoot(analysis.RootDir, wd)
+	}
+
+	// Detect technology and framework (detectors look at the project files)
+	analysis.Technology, analysis.Framework = detectTechnology(analysis)
+
+	// Detect service type
+	analysis.ServiceType = detectServiceType(analysis)
+
+	// Extract sockets and the ports they use
+	sockets, err := lpa.ExtractSocketsFromProcess(pid)
+	if err == nil {
+		analysis.Sockets = sockets
+		analysis.DetectedPorts = socketPorts(sockets)
+	}
+
+	return analysis, nil
+}
+
+func (lpa *LinuxProcessAnalyzer) getProcessDetails(pid int) (string, []string, string, string, error) {
+	// Get process name
+	processName, err := readProcName(pid)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+
+	// Get argv
+	args, err := readProcArgs(pid)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+
+	// Get working directory (unreadable for other users' processes without root)
+	workingDir, err := os.Readlink(filepath.Join(procDir, strconv.Itoa(pid), "cwd"))
+	if err != nil {
+		workingDir = ""
+	}
+
+	// Get user
+	status, err := readProcStatus(pid)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+	uid, err := uidFromStatus(status)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+
+	return processName, args, workingDir, lookupUsername(uid), nil
+}
+
+func (lpa *LinuxProcessAnalyzer) FindProjectRoot(workingDir string) (string, []string) {
+	return findProjectRoot("", workingDir)
+}
+
+// ExtractPortsFromProcess reports the local port of listening and bound
+// sockets and the remote port of connected ones, like the lsof based analyzer
+func (lpa *LinuxProcessAnalyzer) ExtractPortsFromProcess(pid int) ([]int, error) {
+	sockets, err := lpa.ExtractSocketsFromProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	return socketPorts(sockets), nil
+}
+
+// ExtractSocketsFromProcess matches the process's socket inodes against
+// /proc/net
+func (lpa *LinuxProcessAnalyzer) ExtractSocketsFromProcess(pid int) ([]ProcessSocket, error) {
+	inodes, err := socketInodes(pid)
+	if err != nil {
+		return nil, err
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	held := make(map[uint64]bool, len(inodes))
+	for _, inode := range inodes {
+		held[inode] = true
+	}
+
+	entries, err := readSocketTables()
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets []ProcessSocket
+	for _, entry := range entries {
+		if !held[entry.Inode] {
+			continue
+		}
+		socket := ProcessSocket{
+			Protocol:     entry.Protocol,
+			LocalAddress: entry.LocalIP.String(),
+			LocalPort:    entry.LocalPort,
+			Listening:    entry.listening(),
+		}
+		if entry.RemotePort != 0 {
+			socket.RemoteAddress = entry.RemoteIP.String()
+			socket.RemotePort = entry.RemotePort
+		}
+		sockets = append(sockets, socket)
+	}
+	sortSockets(sockets)
+	return sockets, nil
+}
+
+// FindProcesses matches name against /proc/<pid>/comm, which the kernel
+// cuts to 15 characters, and against the base name of argv[0]
+func (lpa *LinuxProcessAnalyzer) FindProcesses(name string) ([]int, error) {
+	pids, err := listPIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []int
+	for _, pid := range pids {
+		if pid == os.Getpid() {
+			continue
+		}
+		comm, err := readProcName(pid)
+		if err != nil {
+			continue // exited
+		}
+		if comm == name {
+			matches = append(matches, pid)
+		} else if args, err := readProcArgs(pid); err == nil && len(args) > 0 && filepath.Base(args[0]) == name {
+			matches = append(matches, pid)
+		}
+	}
+	return matches, nil
+}
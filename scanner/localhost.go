@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// LocalhostMismatch explains why clients connecting to "localhost" may not
+// reach a listener, e.g. Node 17+ resolving localhost to ::1 while the
+// server only listens on 127.0.0.1
+type LocalhostMismatch struct {
+	Resolved []string // what localhost resolves to, in resolver order
+	Warning  string
+	Fix      string
+}
+
+// ResolveLocalhost resolves "localhost" through the system resolver,
+// keeping the order clients see
+func ResolveLocalhost() ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, "localhost")
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// CheckLocalhost compares what localhost resolves to with the addresses
+// status is bound on. Clients that don't fall back (Node 17+, many HTTP
+// libraries) only try the first address, so that is the one that must be
+// served. It returns nil when there is nothing to warn about.
+func CheckLocalhost(status *PortStatus, resolved []net.IP) *LocalhostMismatch {
+	if len(status.Addresses) == 0 || len(resolved) == 0 {
+		return nil
+	}
+
+	dualWildcard := v6WildcardIsDual()
+	var served []net.IP
+	for _, ip := range resolved {
+		if accepts(status.Addresses, ip, dualWildcard) {
+			served = append(served, ip)
+		}
+	}
+	if len(served) > 0 && served[0].Equal(resolved[0]) {
+		return nil
+	}
+
+	mismatch := &LocalhostMismatch{}
+	for _, ip := range resolved {
+		mismatch.Resolved = append(mismatch.Resolved, ip.String())
+	}
+	bound := strings.Join(status.Addresses, ", ")
+	first := resolved[0]
+
+	switch {
+	case first.To4() == nil && status.Family == FamilyIPv4:
+		mismatch.Warning = fmt.Sprintf("localhost resolves to %s first, but port %d is IPv4 only (%s): Node 17+ and other clients without fallback get ECONNREFUSED while curl and browsers work",
+			first, status.Port, bound)
+		mismatch.Fix = "Listen on :: (dual-stack) instead of 127.0.0.1, or connect to 127.0.0.1 explicitly (Node: --dns-result-order=ipv4first)"
+	case first.To4() != nil && status.Family == FamilyIPv6:
+		mismatch.Warning = fmt.Sprintf("localhost resolves to %s first, but port %d is IPv6 only (%s): clients without fallback get ECONNREFUSED",
+			first, status.Port, bound)
+		mismatch.Fix = "Listen on 0.0.0.0 or a dual-stack :: as well, or connect to [::1] explicitly"
+	case len(served) == 0:
+		mismatch.Warning = fmt.Sprintf("localhost resolves to %s, but port %d is only bound on %s: nothing reaches it through localhost",
+			strings.Join(mismatch.Resolved, ", "), status.Port, bound)
+		mismatch.Fix = "Bind the server to 127.0.0.1 and ::1, or to 0.0.0.0 / :: for every interface"
+	default:
+		mismatch.Warning = fmt.Sprintf("localhost resolves to %s first, but port %d is not bound on it (%s): clients without fallback get ECONNREFUSED",
+			first, status.Port, bound)
+		mismatch.Fix = fmt.Sprintf("Also listen on %s, or on 0.0.0.0 / :: for every interface", first)
+	}
+	return mismatch
+}
+
+// accepts reports whether a socket bound on one of addresses receives
+// connections to ip
+func accepts(addresses []string, ip net.IP, dualWildcard bool) bool {
+	for _, addr := range addresses {
+		bound := net.ParseIP(addr)
+		switch {
+		case bound == nil:
+			continue
+		case bound.Equal(ip):
+			return true
+		case bound.Equal(net.IPv4zero) && ip.To4() != nil:
+			return true
+		case bound.Equal(net.IPv6unspecified) && (ip.To4() == nil || dualWildcard):
+			return true
+		}
+	}
+	return false
+}
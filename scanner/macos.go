@@ -1,134 +1,390 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
-type MacScanner struct{}
+type MacScanner struct {
+	bindAddress  string
+	dockerSocket string
+}
 
 func (ms *MacScanner) CheckPort(port int) (*PortStatus, error) {
-	status := &PortStatus{Port: port}
+	statuses, err := ms.CheckPorts(context.Background(), []PortSpec{{Port: port, Protocol: ProtocolTCP}})
+	if err != nil {
+		return nil, err
+	}
+	return statuses[0], nil
+}
+
+// CheckPorts bind-checks all ports concurrently, then resolves owners with a
+// single lsof run and a single ps run. Results are in input order.
+func (ms *MacScanner) CheckPorts(ctx context.Context, specs []PortSpec) ([]*PortStatus, error) {
+	bindErrs, err := checkAvailability(ctx, specs, ms.checkBind)
+	if err != nil {
+		return nil, err
+	}
 
-	if ms.isPortAvailable(port) {
-		status.IsAvailable = true
-		return status, nil
+	statuses := make([]*PortStatus, len(specs))
+	var unavailable []*PortStatus
+	for i, spec := range specs {
+		statuses[i] = &PortStatus{Port: spec.Port, Protocol: spec.Protocol, IsAvailable: bindErrs[i] == nil}
+		if bindErrs[i] != nil {
+			statuses[i].Reason = bindFailureReason(bindErrs[i])
+			statuses[i].BindError = bindErrs[i].Error()
+			unavailable = append(unavailable, statuses[i])
+		}
+	}
+	// A port that is free on --bind may still be bound on another address
+	if len(unavailable) == 0 && ms.bindAddress == "" {
+		return statuses, nil
 	}
 
-	process, pid, err := ms.findWithLsof(port)
+	rows, err := ms.lsofSnapshot(ctx)
 	if err != nil {
-		status.Error = err.Error()
-		return status, nil
+		for _, status := range unavailable {
+			if status.Reason == ReasonInUse {
+				status.Error = err.Error()
+				status.ErrorCode = ErrCodeOwnerLookup
+			}
+		}
+		attachContainers(ctx, statuses, ms.dockerSocket)
+		return statuses, nil
 	}
 
-	status.IsAvailable = false
-	status.ProcessName = process
-	status.PID = pid
+	var occupied []*PortStatus
+	for _, status := range statuses {
+		recordAddresses(status, lsofAddresses(rows, status.Spec()))
+		// A denied bind can still hide a listener
+		if status.Reason == ReasonPermissionDenied && len(status.Addresses) > 0 {
+			status.Reason = ReasonInUse
+		}
+		if status.Reason == ReasonInUse {
+			occupied = append(occupied, status)
+		}
+	}
+
+	var pids []int
+	seen := make(map[int]bool)
+	holders := make(map[*PortStatus][]int)
+	// lsof's COMMAND column is what we've always shown as the process name
+	commands := make(map[int]string)
+	for _, status := range occupied {
+		found, err := lsofOwners(rows, status.Spec())
+		if err != nil {
+			// lsof doesn't list TIME_WAIT sockets, they have no process
+			if status.Protocol == ProtocolTCP && ms.lingering(ctx, status.Port) {
+				status.Reason = ReasonTimeWait
+				continue
+			}
+			status.Error = err.Error()
+			status.ErrorCode = ErrCodeOwnerLookup
+			continue
+		}
+		for _, row := range found {
+			holders[status] = append(holders[status], row.pid)
+			commands[row.pid] = row.command
+			if !seen[row.pid] {
+				seen[row.pid] = true
+				pids = append(pids, row.pid)
+			}
+		}
+	}
 
 	//detailed
-	status.User = ms.getProcessUser(pid)
-	status.CommandLine = ms.getCommandLine(pid)
-	status.MemoryUsage = ms.getMemoryUsage(pid)
-	status.StartTime = ms.getStartTime(pid)
+	owners := ms.getOwnerDetails(ctx, pids)
+	for pid, details := range owners {
+		details.name = commands[pid]
+	}
+	lookup := func(pid int) *ownerDetails {
+		details, ok := owners[pid]
+		if !ok {
+			// Parents that hold no socket weren't part of the first ps run
+			details = ms.getOwnerDetails(ctx, []int{pid})[pid]
+			owners[pid] = details
+		}
+		return details
+	}
 
-	return status, nil
+	for _, status := range occupied {
+		pids, ok := holders[status]
+		if !ok {
+			continue
+		}
+		status.Owners = resolveOwners(pids, lookup)
+		if len(status.Owners) == 0 {
+			// ps couldn't describe the process, but lsof still named it
+			status.PID = pids[0]
+			status.ProcessName = commands[pids[0]]
+			continue
+		}
+		status.PID = status.Owners[0].PID
+		lookup(status.PID).apply(status)
+	}
+
+	attachContainers(ctx, statuses, ms.dockerSocket)
+	return statuses, nil
 }
 
-func (ms *MacScanner) getProcessUser(pid int) string {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "user=")
-	output, err := cmd.Output()
-	if err != nil {
-		return "unknown"
-	}
-	return strings.TrimSpace(string(output))
+func (ms *MacScanner) Backend() string {
+	return BackendLsof
 }
 
-func (ms *MacScanner) getCommandLine(pid int) string {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "command=")
-	output, err := cmd.Output()
-	if err != nil {
-		return "unknown"
-	}
-	return strings.TrimSpace(string(output))
+func (ms *MacScanner) BindAddress() string {
+	return ms.bindAddress
 }
 
-func (ms *MacScanner) getMemoryUsage(pid int) string {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "rss=")
-	output, err := cmd.Output()
+// Listening lists the listening ports from one lsof run
+func (ms *MacScanner) Listening(ctx context.Context) ([]PortSpec, error) {
+	rows, err := ms.lsofSnapshot(ctx)
 	if err != nil {
-		return "unknown"
+		return nil, err
 	}
-	rss := strings.TrimSpace(string(output))
-	if rssKB, err := strconv.Atoi(rss); err == nil {
-		return fmt.Sprintf("%dMB", rssKB/1024)
+
+	seen := make(map[PortSpec]bool)
+	for _, row := range rows {
+		// lsof prints no state for UDP, only a peer when connected
+		if row.listening || (row.protocol == ProtocolUDP && row.remotePort == 0) {
+			seen[PortSpec{Port: row.localPort, Protocol: row.protocol}] = true
+		}
 	}
-	return rss + "KB"
+	return sortSpecs(seen), nil
 }
 
-func (ms *MacScanner) getStartTime(pid int) string {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "lstart=")
-	output, err := cmd.Output()
-	if err != nil {
-		return "unknown"
-	}
-	return strings.TrimSpace(string(output))
+func (ms *MacScanner) checkBind(spec PortSpec) error {
+	return tryBind(ms.bindAddress, spec)
 }
 
-func (ms *MacScanner) isPortAvailable(port int) bool {
-	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+// lingering reports whether the TCP port is only held by connections in
+// TIME_WAIT, going by netstat lines like
+// "tcp4  0  0  127.0.0.1.3000  127.0.0.1.51234  TIME_WAIT"
+func (ms *MacScanner) lingering(ctx context.Context, port int) bool {
+	output, err := exec.CommandContext(ctx, "netstat", "-an", "-p", "tcp").Output()
 	if err != nil {
 		return false
 	}
-	ln.Close()
-	return true
+
+	suffix := "." + strconv.Itoa(port)
+	timeWait := false
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !strings.HasSuffix(fields[3], suffix) {
+			continue
+		}
+		if fields[5] != "TIME_WAIT" {
+			return false
+		}
+		timeWait = true
+	}
+	return timeWait
+}
+
+type lsofRow struct {
+	command   string
+	pid       int
+	protocol  string // ProtocolTCP or ProtocolUDP
+	localIP   net.IP
+	localPort int
+	listening bool
+	// Set for connected sockets, "local->remote"
+	remoteIP   net.IP
+	remotePort int
 }
 
-func (ms *MacScanner) findWithLsof(port int) (string, int, error) {
-	// Use full lsof output to get both PID and command
-	cmd := exec.Command("lsof", "-i", ":"+strconv.Itoa(port), "-P")
+// lsofSnapshot lists every internet socket on the machine in one lsof run
+func (ms *MacScanner) lsofSnapshot(ctx context.Context) ([]lsofRow, error) {
+	cmd := exec.CommandContext(ctx, "lsof", "-nP", "-i")
 	output, err := cmd.Output()
 	if err != nil {
-		return "", 0, err
+		// lsof exits 1 with no output when there is nothing to list
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || len(output) > 0 {
+			return nil, err
+		}
 	}
 
+	var rows []lsofRow
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) <= 1 { // First line is header
-		return "", 0, fmt.Errorf("no lsof output")
-	}
-
-	// Parse the first data line (skip header)
 	for i, line := range lines {
 		if i == 0 { // Skip header
 			continue
 		}
-		if line == "" {
-			continue
+		if row, ok := parseLsofLine(line); ok {
+			rows = append(rows, row)
 		}
+	}
+	return rows, nil
+}
 
-		// Parse: "Python  28024 user  8u  IPv4 ..."
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			pid, err := strconv.Atoi(fields[1])
-			if err != nil {
-				continue // Skip if PID not parseable
-			}
+// parseLsofLine parses
+// "node  4521 arjun  23u  IPv6 0x1234  0t0  TCP *:3000 (LISTEN)" or
+// "Python 28024 arjun 8u IPv4 0x5678 0t0 TCP 127.0.0.1:5000->127.0.0.1:61000 (ESTABLISHED)"
+func parseLsofLine(line string) (lsofRow, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 9 {
+		return lsofRow{}, false
+	}
+
+	pid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return lsofRow{}, false
+	}
 
-			processName := fields[0]
-			return processName, pid, nil
+	local, remote, _ := strings.Cut(fields[8], "->")
+	idx := strings.LastIndex(local, ":")
+	if idx == -1 {
+		return lsofRow{}, false
+	}
+	port, err := strconv.Atoi(local[idx+1:])
+	if err != nil { // "*:*"
+		return lsofRow{}, false
+	}
+
+	row := lsofRow{
+		command:   fields[0],
+		pid:       pid,
+		protocol:  strings.ToLower(fields[7]),
+		localIP:   parseLsofHost(local[:idx], fields[4]),
+		localPort: port,
+		listening: len(fields) > 9 && fields[9] == "(LISTEN)",
+	}
+	if idx := strings.LastIndex(remote, ":"); idx != -1 {
+		if port, err := strconv.Atoi(remote[idx+1:]); err == nil {
+			row.remoteIP = parseLsofHost(remote[:idx], fields[4])
+			row.remotePort = port
 		}
 	}
+	return row, true
+}
 
-	return "", 0, fmt.Errorf("no process found in lsof output")
+// parseLsofHost parses "127.0.0.1", "[::1]" or "[fe80::1%lo0]"; "*" is the
+// wildcard of family ("IPv4" or "IPv6")
+func parseLsofHost(host, family string) net.IP {
+	if host == "*" {
+		if family == "IPv6" {
+			return net.IPv6unspecified
+		}
+		return net.IPv4zero
+	}
+	host = strings.Trim(host, "[]")
+	host, _, _ = strings.Cut(host, "%")
+	return net.ParseIP(host)
 }
 
-func (ms *MacScanner) getProcessName(pid int) (string, error) {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=")
+// lsofAddresses returns the local addresses spec is bound on. For TCP these
+// are the listeners' addresses, unless nothing listens.
+func lsofAddresses(rows []lsofRow, spec PortSpec) []net.IP {
+	var listening, all []net.IP
+	for _, row := range rows {
+		if row.localPort != spec.Port || row.protocol != spec.Protocol || row.localIP == nil {
+			continue
+		}
+		all = append(all, row.localIP)
+		if row.listening {
+			listening = append(listening, row.localIP)
+		}
+	}
+	if len(listening) > 0 {
+		return listening
+	}
+	return all
+}
+
+// lsofOwners picks the rows holding spec. For TCP, listeners win over other
+// sockets bound to the same local port.
+func lsofOwners(rows []lsofRow, spec PortSpec) ([]lsofRow, error) {
+	var listeners, others []lsofRow
+	for _, row := range rows {
+		if row.localPort != spec.Port || row.protocol != spec.Protocol {
+			continue
+		}
+		if row.listening {
+			listeners = append(listeners, row)
+		} else {
+			others = append(others, row)
+		}
+	}
+
+	if len(listeners) > 0 {
+		return listeners, nil
+	}
+	if len(others) > 0 {
+		return others, nil
+	}
+	return nil, fmt.Errorf("no %s process found in lsof output", spec.Protocol)
+}
+
+// getOwnerDetails resolves user, memory, start time and command line for all
+// pids with one ps run
+func (ms *MacScanner) getOwnerDetails(ctx context.Context, pids []int) map[int]*ownerDetails {
+	owners := make(map[int]*ownerDetails)
+	if len(pids) == 0 {
+		return owners
+	}
+
+	pidList := make([]string, len(pids))
+	for i, pid := range pids {
+		pidList[i] = strconv.Itoa(pid)
+	}
+
+	cmd := exec.CommandContext(ctx, "ps", "-p", strings.Join(pidList, ","), "-o", "pid=,ppid=,user=,rss=,lstart=,command=")
 	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		return owners
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		pid, details, ok := parsePsLine(line)
+		if ok {
+			owners[pid] = details
+		}
+	}
+	return owners
+}
+
+// parsePsLine parses "4521 4490 arjun 81234 Thu Oct 16 10:02:11 2026 node server.js"
+func parsePsLine(line string) (int, *ownerDetails, bool) {
+	fields := strings.Fields(line)
+	// pid, ppid, user, rss, five lstart fields, then the command
+	if len(fields) < 10 {
+		return 0, nil, false
+	}
+
+	pid, err := strconv.Atoi(fields[0])
 	if err != nil {
-		return "", err
+		return 0, nil, false
 	}
-	return strings.TrimSpace(string(output)), nil
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, nil, false
+	}
+
+	details := &ownerDetails{
+		ppid:        ppid,
+		name:        filepath.Base(fields[9]),
+		user:        fields[2],
+		commandLine: strings.Join(fields[9:], " "),
+		memoryUsage: fields[3] + "KB",
+	}
+
+	if rssKB, err := strconv.Atoi(fields[3]); err == nil {
+		details.memoryUsage = fmt.Sprintf("%dMB", rssKB/1024)
+		details.memoryBytes = int64(rssKB) * 1024
+	}
+
+	// lstart uses the ANSIC layout: "Mon Jan  2 15:04:05 2006"
+	details.startTime = strings.Join(fields[4:9], " ")
+	if started, err := time.ParseInLocation(time.ANSIC, details.startTime, time.Local); err == nil {
+		details.started = started
+	}
+
+	return pid, details, true
 }
@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// From <linux/sock_diag.h> and <linux/inet_diag.h>
+const (
+	netlinkSockDiag  = 4  // NETLINK_SOCK_DIAG
+	sockDiagByFamily = 20 // SOCK_DIAG_BY_FAMILY
+	inetDiagReqV2Len = 56 // struct inet_diag_req_v2
+	inetDiagMsgLen   = 72 // struct inet_diag_msg
+	allSocketStates  = 0xFFFFFFFF
+)
+
+var netlinkTables = []struct {
+	family   uint8
+	protocol uint8
+	name     string
+}{
+	{syscall.AF_INET, syscall.IPPROTO_TCP, ProtocolTCP},
+	{syscall.AF_INET6, syscall.IPPROTO_TCP, ProtocolTCP},
+	{syscall.AF_INET, syscall.IPPROTO_UDP, ProtocolUDP},
+	{syscall.AF_INET6, syscall.IPPROTO_UDP, ProtocolUDP},
+}
+
+// netlinkSocketTables lists the same sockets as readSocketTables, but with
+// one inet_diag dump per family and protocol instead of parsing text. Any
+// table failing fails the whole list, so a missing udp_diag module can't
+// silently drop every UDP socket.
+func netlinkSocketTables() ([]socketEntry, error) {
+	fd, err := openSockDiag()
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	var entries []socketEntry
+	for i, table := range netlinkTables {
+		tableEntries, err := inetDiagDump(fd, uint32(i+1), table.family, table.protocol, allSocketStates, table.name)
+		if table.family == syscall.AF_INET6 && errors.Is(err, syscall.EAFNOSUPPORT) {
+			// IPv6 is disabled, so there are no IPv6 sockets to list
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, tableEntries...)
+	}
+	return entries, nil
+}
+
+// probeNetlink checks that sock_diag is permitted here with dumps of IPv4
+// TCP listeners and unconnected UDP sockets, the smallest useful requests.
+// Containers and sandboxes often refuse it, and udp_diag is a module of its
+// own that may not be loaded.
+func probeNetlink() error {
+	fd, err := openSockDiag()
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	if _, err := inetDiagDump(fd, 1, syscall.AF_INET, syscall.IPPROTO_TCP, 1<<tcpListen, ProtocolTCP); err != nil {
+		return err
+	}
+	_, err = inetDiagDump(fd, 2, syscall.AF_INET, syscall.IPPROTO_UDP, 1<<udpUnconnected, ProtocolUDP)
+	return err
+}
+
+func openSockDiag() (int, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, netlinkSockDiag)
+	if err != nil {
+		return -1, fmt.Errorf("netlink sock_diag: %w", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("netlink sock_diag: %w", err)
+	}
+	return fd, nil
+}
+
+// inetDiagDump sends one SOCK_DIAG_BY_FAMILY dump request and collects the
+// replies until NLMSG_DONE. states is a bitmask of 1<<state.
+func inetDiagDump(fd int, seq uint32, family, protocol uint8, states uint32, name string) ([]socketEntry, error) {
+	ne := binary.NativeEndian
+
+	req := make([]byte, syscall.NLMSG_HDRLEN+inetDiagReqV2Len)
+	ne.PutUint32(req[0:4], uint32(len(req)))
+	ne.PutUint16(req[4:6], sockDiagByFamily)
+	ne.PutUint16(req[6:8], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	ne.PutUint32(req[8:12], seq)
+	body := req[syscall.NLMSG_HDRLEN:]
+	body[0] = family
+	body[1] = protocol
+	ne.PutUint32(body[4:8], states)
+
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("netlink sock_diag: %w", err)
+	}
+
+	var entries []socketEntry
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("netlink sock_diag: %w", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("netlink sock_diag: %w", err)
+		}
+		for _, msg := range msgs {
+			if msg.Header.Seq != seq {
+				continue
+			}
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return entries, nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := -int32(ne.Uint32(msg.Data[:4])); errno != 0 {
+						return nil, fmt.Errorf("netlink sock_diag: %w", syscall.Errno(errno))
+					}
+				}
+				return nil, fmt.Errorf("netlink sock_diag: malformed error reply")
+			case sockDiagByFamily:
+				if entry, ok := parseInetDiagMsg(msg.Data, name); ok {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+}
+
+// parseInetDiagMsg decodes a struct inet_diag_msg. Ports and addresses are
+// in network byte order, everything else is native.
+func parseInetDiagMsg(data []byte, protocol string) (socketEntry, bool) {
+	if len(data) < inetDiagMsgLen {
+		return socketEntry{}, false
+	}
+	ne := binary.NativeEndian
+
+	addrLen := net.IPv4len
+	if data[0] == syscall.AF_INET6 {
+		addrLen = net.IPv6len
+	}
+	// struct inet_diag_sockid starts at offset 4: sport, dport, src[16], dst[16], if, cookie
+	id := data[4:52]
+
+	return socketEntry{
+		Protocol:   protocol,
+		LocalIP:    net.IP(append([]byte(nil), id[4:4+addrLen]...)),
+		LocalPort:  int(binary.BigEndian.Uint16(id[0:2])),
+		RemoteIP:   net.IP(append([]byte(nil), id[20:20+addrLen]...)),
+		RemotePort: int(binary.BigEndian.Uint16(id[2:4])),
+		State:      int(data[1]),
+		RxQueue:    int(ne.Uint32(data[56:60])),
+		TxQueue:    int(ne.Uint32(data[60:64])),
+		UID:        int(ne.Uint32(data[64:68])),
+		Inode:      uint64(ne.Uint32(data[68:72])),
+	}, true
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package scanner
+
+import "errors"
+
+var errNoNetlink = errors.New("netlink sock_diag is only available on Linux")
+
+func netlinkSocketTables() ([]socketEntry, error) {
+	return nil, errNoNetlink
+}
+
+func probeNetlink() error {
+	return errNoNetlink
+}
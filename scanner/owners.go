@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// How an Owner relates to the port
+const (
+	RelationListener = "listener" // holds a socket on the port itself
+	RelationWorker   = "worker"   // shares a socket inherited from its parent, e.g. gunicorn or nginx workers
+	RelationParent   = "parent"   // launched the listener without holding the port, e.g. npm → node
+)
+
+// Owner is one process involved in holding a port
+type Owner struct {
+	PID          int
+	PPID         int
+	Name         string
+	User         string
+	CommandLine  string
+	Relationship string // one of the Relation constants
+}
+
+// launchers are parents not worth reporting: shells, terminals and init
+// systems start everything, so they say nothing about the service
+var launchers = map[string]bool{
+	"bash": true, "sh": true, "zsh": true, "fish": true, "dash": true, "ksh": true, "tcsh": true, "csh": true,
+	"login": true, "sshd": true, "su": true, "sudo": true, "tmux": true, "screen": true,
+	"init": true, "systemd": true, "launchd": true, "tini": true, "docker-init": true, "dumb-init": true,
+}
+
+// resolveOwners classifies the processes holding one port: holders whose
+// parent also holds it are workers, the others listeners. The first
+// listener's parent is added when it is more than a shell. The result is
+// ordered listeners, workers, parent, so the first owner is the one that
+// actually owns the socket. lookup returns nil for processes that are gone.
+func resolveOwners(pids []int, lookup func(pid int) *ownerDetails) []Owner {
+	sort.Ints(pids)
+	holders := make(map[int]*ownerDetails, len(pids))
+	for _, pid := range pids {
+		if details := lookup(pid); details != nil {
+			holders[pid] = details
+		}
+	}
+
+	var listeners, workers []Owner
+	for _, pid := range pids {
+		details, ok := holders[pid]
+		if !ok {
+			continue
+		}
+		owner := details.owner(pid, RelationListener)
+		if _, parentHolds := holders[details.ppid]; parentHolds {
+			owner.Relationship = RelationWorker
+			workers = append(workers, owner)
+		} else {
+			listeners = append(listeners, owner)
+		}
+	}
+
+	owners := append(listeners, workers...)
+	if len(listeners) > 0 && listeners[0].PPID > 1 {
+		if parent := lookup(listeners[0].PPID); parent != nil && !launchers[baseCommand(parent.name)] {
+			owners = append(owners, parent.owner(listeners[0].PPID, RelationParent))
+		}
+	}
+	return owners
+}
+
+// baseCommand strips the "-" of login shells and the "tmux: server" style
+// suffixes some processes use
+func baseCommand(name string) string {
+	name = strings.TrimPrefix(name, "-")
+	for i, r := range name {
+		if r == ':' || r == ' ' {
+			return name[:i]
+		}
+	}
+	return name
+}
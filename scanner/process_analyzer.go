@@ -1,11 +1,15 @@
 package scanner
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"portscanner/scanner/detectors"
 )
 
 type MacProcessAnalyzer struct{}
@@ -27,24 +31,27 @@ func (mpa *MacProcessAnalyzer) AnalyzeProcess(pid int) (*ProcessAnalysis, error)
 
 	analysis.Name = name
 	analysis.CommandLine = cmd
+	// ps joins argv with spaces, so quoted arguments can't be recovered here
+	analysis.Args = strings.Fields(cmd)
 	analysis.WorkingDir = wd
 	analysis.User = user
 
-	// Detect technology
-	analysis.Technology = mpa.detectTechnology(analysis)
-
-	// Detect service type
-	analysis.ServiceType = mpa.detectServiceType(analysis)
-
 	// Find project root
 	projectPath, configFiles := mpa.FindProjectRoot(wd)
 	analysis.ProjectPath = projectPath
 	analysis.ConfigFiles = configFiles
 
-	// Extract ports
-	ports, err := mpa.ExtractPortsFromProcess(pid)
+	// Detect technology and framework (detectors look at the project files)
+	analysis.Technology, analysis.Framework = detectTechnology(analysis)
+
+	// Detect service type
+	analysis.ServiceType = detectServiceType(analysis)
+
+	// Extract sockets and the ports they use
+	sockets, err := mpa.ExtractSocketsFromProcess(pid)
 	if err == nil {
-		analysis.DetectedPorts = ports
+		analysis.Sockets = sockets
+		analysis.DetectedPorts = socketPorts(sockets)
 	}
 
 	return analysis, nil
@@ -92,64 +99,46 @@ func (mpa *MacProcessAnalyzer) getProcessDetails(pid int) (string, string, strin
 	return processName, commandLine, workingDir, user, nil
 }
 
-func (mpa *MacProcessAnalyzer) detectTechnology(analysis *ProcessAnalysis) string {
-	cmd := strings.ToLower(analysis.CommandLine)
-	name := strings.ToLower(analysis.Name)
-
-	// Node.js detection
-	if strings.Contains(cmd, "node") || strings.Contains(cmd, "npm") ||
-		strings.Contains(cmd, "npx") || name == "node" {
-		return "node"
-	}
-
-	// Python detection
-	if strings.Contains(cmd, "python") || strings.Contains(cmd, "python3") ||
-		strings.Contains(cmd, "streamlit") || strings.Contains(cmd, "fastapi") ||
-		strings.Contains(cmd, "flask") || strings.Contains(cmd, "django") {
-		return "python"
-	}
-
-	// Postgres detection
-	if strings.Contains(name, "postgres") || strings.Contains(cmd, "postgres") {
-		return "postgres"
+// detectTechnology runs the detector registry and returns the technology and
+// framework of the best match
+func detectTechnology(analysis *ProcessAnalysis) (string, string) {
+	process := &detectors.Process{
+		Name:        analysis.Name,
+		CommandLine: analysis.CommandLine,
+		Args:        analysis.Args,
+		WorkingDir:  analysis.WorkingDir,
 	}
 
-	// Redis detection
-	if strings.Contains(name, "redis") || strings.Contains(cmd, "redis") {
-		return "redis"
+	var files fs.FS
+	if analysis.ProjectPath != "" {
+		files = os.DirFS(analysis.ProjectPath)
 	}
 
-	// Java detection
-	if strings.Contains(cmd, "java") || strings.Contains(name, "java") {
-		return "java"
-	}
-
-	// Go detection
-	if strings.Contains(cmd, "go") && !strings.Contains(cmd, "google") {
-		return "go"
-	}
-
-	// Browser detection
-	if strings.Contains(name, "firefox") || strings.Contains(name, "chrome") ||
-		strings.Contains(name, "safari") {
-		return "browser"
-	}
-
-	return "unknown"
+	match := detectors.Default().Detect(process, files)
+	return match.Technology, match.Framework
 }
 
-func (mpa *MacProcessAnalyzer) detectServiceType(analysis *ProcessAnalysis) string {
+func detectServiceType(analysis *ProcessAnalysis) string {
 	switch analysis.Technology {
-	case "node", "python", "go", "java":
-		// Check if it's a web server
-		if strings.Contains(strings.ToLower(analysis.CommandLine), "server") ||
-			strings.Contains(strings.ToLower(analysis.CommandLine), "start") ||
-			strings.Contains(strings.ToLower(analysis.CommandLine), "run") ||
-			strings.Contains(strings.ToLower(analysis.CommandLine), "dev") {
+	case "node", "python", "go", "java", "ruby", "php", "rust", "dotnet":
+		// Every framework we detect is a web framework
+		if analysis.Framework != "" {
 			return "web"
 		}
+		// Otherwise look for a server-ish argument ("serve", "server.js", "dev")
+		for i, arg := range analysis.Args {
+			if i == 0 {
+				continue
+			}
+			name := strings.ToLower(filepath.Base(arg))
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			switch name {
+			case "server", "serve", "runserver", "start", "dev", "run":
+				return "web"
+			}
+		}
 		return "cli"
-	case "postgres", "mysql", "mongod":
+	case "postgres", "mysql", "mongodb":
 		return "database"
 	case "redis", "memcached":
 		return "cache"
@@ -161,9 +150,22 @@ func (mpa *MacProcessAnalyzer) detectServiceType(analysis *ProcessAnalysis) stri
 }
 
 func (mpa *MacProcessAnalyzer) FindProjectRoot(workingDir string) (string, []string) {
+	return findProjectRoot("", workingDir)
+}
+
+// findProjectRoot walks up from workingDir until a directory containing a
+// project marker is found. It is shared by every ProcessAnalyzer. A
+// non-empty root, such as /proc/<pid>/root, is where workingDir's filesystem
+// is mounted: the walk stops there and the returned paths start with it.
+func findProjectRoot(root, workingDir string) (string, []string) {
 	if workingDir == "" {
 		return "", []string{}
 	}
+	top := "/"
+	if root != "" {
+		top = filepath.Clean(root)
+		workingDir = filepath.Join(root, workingDir)
+	}
 
 	dir := workingDir
 	var configFiles []string
@@ -181,7 +183,7 @@ func (mpa *MacProcessAnalyzer) FindProjectRoot(workingDir string) (string, []str
 		"composer.json",  // PHP
 	}
 
-	for dir != "/" {
+	for dir != top {
 		// Check for project markers
 		for _, marker := range projectMarkers {
 			markerPath := filepath.Join(dir, marker)
@@ -206,56 +208,79 @@ func (mpa *MacProcessAnalyzer) FindProjectRoot(workingDir string) (string, []str
 	return workingDir, configFiles
 }
 
+// ExtractPortsFromProcess reports the local port of listening and bound
+// sockets and the remote port of connected ones
 func (mpa *MacProcessAnalyzer) ExtractPortsFromProcess(pid int) ([]int, error) {
-	var ports []int
+	sockets, err := mpa.ExtractSocketsFromProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	return socketPorts(sockets), nil
+}
 
-	// Use lsof to find network connections for this process
-	cmd := exec.Command("lsof", "-p", strconv.Itoa(pid), "-i", "-P", "-n")
+// ExtractSocketsFromProcess lists the process's internet sockets with lsof
+func (mpa *MacProcessAnalyzer) ExtractSocketsFromProcess(pid int) ([]ProcessSocket, error) {
+	// -a: sockets of pid, not pid's files plus every socket
+	cmd := exec.Command("lsof", "-a", "-p", strconv.Itoa(pid), "-i", "-P", "-n")
 	output, err := cmd.Output()
 	if err != nil {
-		return ports, err
+		// lsof exits 1 with no output when the process has no sockets
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || len(output) > 0 {
+			return nil, err
+		}
 	}
 
-	lines := strings.Split(string(output), "\n")
+	var sockets []ProcessSocket
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	for i, line := range lines {
 		if i == 0 { // Skip header
 			continue
 		}
-		if line == "" {
+		row, ok := parseLsofLine(line)
+		if !ok {
 			continue
 		}
-
-		// Parse lsof output to extract port numbers
-		// Format: process PID user FD type ... NODE NAME
-		fields := strings.Fields(line)
-		if len(fields) >= 9 {
-			// Look for port in the NODE NAME field (e.g., *:3000 (LISTEN))
-			nodeField := fields[8]
-			if idx := strings.LastIndex(nodeField, ":"); idx != -1 {
-				portStr := nodeField[idx+1:]
-				// Remove trailing stuff like (LISTEN)
-				if parenIdx := strings.Index(portStr, " "); parenIdx != -1 {
-					portStr = portStr[:parenIdx]
-				}
-				if portStr == "*" {
-					continue
-				}
-				if port, err := strconv.Atoi(portStr); err == nil {
-					// Check if port already in list
-					found := false
-					for _, p := range ports {
-						if p == port {
-							found = true
-							break
-						}
-					}
-					if !found {
-						ports = append(ports, port)
-					}
-				}
-			}
+		socket := ProcessSocket{
+			Protocol:  row.protocol,
+			LocalPort: row.localPort,
+			Listening: row.listening || (row.protocol == ProtocolUDP && row.remotePort == 0),
+		}
+		if row.localIP != nil {
+			socket.LocalAddress = row.localIP.String()
+		}
+		if row.remotePort != 0 {
+			socket.RemoteAddress = row.remoteIP.String()
+			socket.RemotePort = row.remotePort
 		}
+		sockets = append(sockets, socket)
+	}
+	sortSockets(sockets)
+	return sockets, nil
+}
+
+// FindProcesses matches name against the base name of every command ps
+// lists; macOS reports comm as the full executable path
+func (mpa *MacProcessAnalyzer) FindProcesses(name string) ([]int, error) {
+	output, err := exec.Command("ps", "-axo", "pid=,comm=").Output()
+	if err != nil {
+		return nil, err
 	}
 
-	return ports, nil
+	var matches []int
+	for _, line := range strings.Split(string(output), "\n") {
+		pidStr, comm, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		comm = strings.TrimSpace(comm)
+		if comm == name || filepath.Base(comm) == name {
+			matches = append(matches, pid)
+		}
+	}
+	return matches, nil
 }
@@ -1,16 +1,78 @@
 package scanner
 
+import (
+	"runtime"
+	"sort"
+)
+
 type ProcessAnalysis struct {
 	PID           int
 	Name          string
 	CommandLine   string
+	Args          []string // argv, one element per argument
 	WorkingDir    string
 	User          string
-	Technology    string   // "node", "python", "postgres", "redis", "unknown"
-	ServiceType   string   // "web", "database", "cache", "cli", "browser"
-	DetectedPorts []int    // Ports this process is using
-	ProjectPath   string   // Path to project root (if detectable)
-	ConfigFiles   []string // package.json, docker-compose.yml, etc.
+	Technology    string          // "node", "python", "postgres", "redis", "unknown"
+	Framework     string          // "nextjs", "vite", "django", "fastapi", "flask", "rails", "spring-boot", or ""
+	ServiceType   string          // "web", "database", "cache", "cli", "browser"
+	DetectedPorts []int           // Ports this process is using
+	Sockets       []ProcessSocket // Sockets behind DetectedPorts, listeners first
+	ProjectPath   string          // Path to project root (if detectable)
+	ConfigFiles   []string        // package.json, docker-compose.yml, etc.
+
+	// Containers (Linux only). ContainerID comes from the process's cgroup
+	// and is "" outside a container.
+	ContainerID            string
+	ContainerRuntime       string // one of the Runtime constants
+	SeparatePIDNamespace   bool   // PIDs inside differ from the ones we see
+	SeparateMountNamespace bool   // the process sees another filesystem
+	// "/proc/<pid>/root" with SeparateMountNamespace, where the process's
+	// files are reachable from here. ProjectPath and ConfigFiles include it.
+	// It is "" when that root can't be read, and ProjectPath is then "" too.
+	RootDir string
+}
+
+// ProcessSocket is one TCP or UDP socket held by a process
+type ProcessSocket struct {
+	Protocol      string // ProtocolTCP or ProtocolUDP
+	LocalAddress  string
+	LocalPort     int
+	RemoteAddress string // empty when not connected
+	RemotePort    int
+	Listening     bool // a TCP listener, or a UDP socket without a fixed peer
+}
+
+// socketPorts reports the local port of listening and bound sockets and the
+// remote port of connected ones, each port once
+func socketPorts(sockets []ProcessSocket) []int {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, socket := range sockets {
+		port := socket.LocalPort
+		if socket.RemotePort != 0 {
+			port = socket.RemotePort
+		}
+		if port == 0 || seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// sortSockets orders listeners first, then by protocol and local port
+func sortSockets(sockets []ProcessSocket) {
+	sort.SliceStable(sockets, func(i, j int) bool {
+		a, b := sockets[i], sockets[j]
+		if a.Listening != b.Listening {
+			return a.Listening
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.LocalPort < b.LocalPort
+	})
 }
 
 type Dependency struct {
@@ -24,4 +86,14 @@ type ProcessAnalyzer interface {
 	AnalyzeProcess(pid int) (*ProcessAnalysis, error)
 	FindProjectRoot(workingDir string) (string, []string)
 	ExtractPortsFromProcess(pid int) ([]int, error)
+	ExtractSocketsFromProcess(pid int) ([]ProcessSocket, error)
+	// FindProcesses returns the PIDs whose process or executable name is name
+	FindProcesses(name string) ([]int, error)
+}
+
+func NewProcessAnalyzer() ProcessAnalyzer {
+	if runtime.GOOS == "linux" {
+		return NewLinuxProcessAnalyzer()
+	}
+	return NewMacProcessAnalyzer()
 }
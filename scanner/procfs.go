@@ -0,0 +1,354 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const procDir = "/proc"
+
+// Socket states as they appear in the "st" column of /proc/net/{tcp,udp}
+const (
+	tcpEstablished = 0x01
+	tcpTimeWait    = 0x06
+	tcpListen      = 0x0A
+	udpUnconnected = 0x07
+)
+
+// clockTicks is USER_HZ, which is 100 on every Linux architecture we ship for
+const clockTicks = 100
+
+type socketEntry struct {
+	Protocol   string // "tcp" or "udp"
+	LocalIP    net.IP
+	LocalPort  int
+	RemoteIP   net.IP
+	RemotePort int
+	State      int
+	TxQueue    int // bytes queued for sending, or the accept backlog of a listener
+	RxQueue    int // bytes received but not read yet
+	UID        int
+	Inode      uint64
+}
+
+// listening reports whether the socket accepts new traffic: a TCP listener,
+// or a UDP socket bound to a port without a fixed peer
+func (se socketEntry) listening() bool {
+	if se.Protocol == ProtocolUDP {
+		return se.LocalPort != 0 && se.RemotePort == 0
+	}
+	return se.State == tcpListen
+}
+
+var socketTables = []struct {
+	file     string
+	protocol string
+}{
+	{"tcp", ProtocolTCP},
+	{"tcp6", ProtocolTCP},
+	{"udp", ProtocolUDP},
+	{"udp6", ProtocolUDP},
+}
+
+// readSocketTables reads every IPv4 and IPv6 TCP/UDP socket from /proc/net
+func readSocketTables() ([]socketEntry, error) {
+	var entries []socketEntry
+	var lastErr error
+	read := 0
+
+	for _, table := range socketTables {
+		tableEntries, err := readSocketTable(filepath.Join(procDir, "net", table.file), table.protocol)
+		if err != nil {
+			// tcp6/udp6 are missing when IPv6 is disabled
+			lastErr = err
+			continue
+		}
+		read++
+		entries = append(entries, tableEntries...)
+	}
+
+	if read == 0 {
+		return nil, lastErr
+	}
+	return entries, nil
+}
+
+func readSocketTable(path, protocol string) ([]socketEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []socketEntry
+	lineScanner := bufio.NewScanner(file)
+	for i := 0; lineScanner.Scan(); i++ {
+		if i == 0 { // Skip header
+			continue
+		}
+		entry, err := parseSocketLine(lineScanner.Text(), protocol)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, lineScanner.Err()
+}
+
+// parseSocketLine parses one row of /proc/net/tcp and friends:
+// "0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000 0 12345 ..."
+func parseSocketLine(line, protocol string) (socketEntry, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return socketEntry{}, fmt.Errorf("short socket line")
+	}
+
+	localIP, localPort, err := parseHexAddr(fields[1])
+	if err != nil {
+		return socketEntry{}, err
+	}
+	remoteIP, remotePort, err := parseHexAddr(fields[2])
+	if err != nil {
+		return socketEntry{}, err
+	}
+	state, err := strconv.ParseInt(fields[3], 16, 32)
+	if err != nil {
+		return socketEntry{}, err
+	}
+	txHex, rxHex, _ := strings.Cut(fields[4], ":")
+	txQueue, _ := strconv.ParseInt(txHex, 16, 64)
+	rxQueue, _ := strconv.ParseInt(rxHex, 16, 64)
+	uid, err := strconv.Atoi(fields[7])
+	if err != nil {
+		return socketEntry{}, err
+	}
+	inode, err := strconv.ParseUint(fields[9], 10, 64)
+	if err != nil {
+		return socketEntry{}, err
+	}
+
+	return socketEntry{
+		Protocol:   protocol,
+		LocalIP:    localIP,
+		LocalPort:  localPort,
+		RemoteIP:   remoteIP,
+		RemotePort: remotePort,
+		State:      int(state),
+		TxQueue:    int(txQueue),
+		RxQueue:    int(rxQueue),
+		UID:        uid,
+		Inode:      inode,
+	}, nil
+}
+
+// parseHexAddr decodes "0100007F:0BB8". The address is stored as native-endian
+// 32-bit words, so every 4-byte group has to be reversed.
+func parseHexAddr(s string) (net.IP, int, error) {
+	addr, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid socket address: %s", s)
+	}
+
+	raw, err := hex.DecodeString(addr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid socket address: %s", s)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, err
+	}
+	return net.IP(raw), int(port), nil
+}
+
+// listPIDs returns every numeric entry in /proc in ascending order
+func listPIDs() ([]int, error) {
+	dirEntries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, entry := range dirEntries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	// ReadDir sorts by name, so "10" would come before "9"
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// socketInodes returns the inodes of all sockets held open by pid.
+// Processes owned by other users are unreadable without root.
+func socketInodes(pid int) ([]uint64, error) {
+	fdDir := filepath.Join(procDir, strconv.Itoa(pid), "fd")
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var inodes []uint64
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil {
+			continue
+		}
+		// Format: socket:[12345]
+		if !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+		if err == nil {
+			inodes = append(inodes, inode)
+		}
+	}
+	return inodes, nil
+}
+
+// socketOwners walks /proc/<pid>/fd once and maps every socket inode to the
+// processes holding it, in ascending PID order. Pre-fork servers share one
+// socket between a master and its workers.
+func socketOwners() map[uint64][]int {
+	owners := make(map[uint64][]int)
+	pids, err := listPIDs()
+	if err != nil {
+		return owners
+	}
+
+	for _, pid := range pids {
+		inodes, err := socketInodes(pid)
+		if err != nil {
+			continue
+		}
+		for _, inode := range inodes {
+			owners[inode] = append(owners[inode], pid)
+		}
+	}
+	return owners
+}
+
+// readProcStatus parses /proc/<pid>/status into a key/value map
+func readProcStatus(pid int) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return nil, err
+	}
+
+	status := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			status[key] = strings.TrimSpace(value)
+		}
+	}
+	return status, nil
+}
+
+func readProcName(pid int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readProcArgs returns argv with each argument kept separate
+func readProcArgs(pid int) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+
+	data = []byte(strings.TrimRight(string(data), "\x00"))
+	if len(data) == 0 { // Kernel threads have no command line
+		return nil, nil
+	}
+	return strings.Split(string(data), "\x00"), nil
+}
+
+// uidFromStatus returns the real UID from the "Uid:" line
+func uidFromStatus(status map[string]string) (string, error) {
+	fields := strings.Fields(status["Uid"])
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no Uid line in status")
+	}
+	return fields[0], nil
+}
+
+func lookupUsername(uid string) string {
+	if u, err := user.LookupId(uid); err == nil {
+		return u.Username
+	}
+	return uid
+}
+
+// rssKB returns the resident set size from the "VmRSS:" line
+func rssKB(status map[string]string) (int, error) {
+	fields := strings.Fields(status["VmRSS"])
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("no VmRSS line in status")
+	}
+	return strconv.Atoi(fields[0])
+}
+
+// readProcStartTime combines the starttime field of /proc/<pid>/stat with the
+// boot time from /proc/stat
+func readProcStartTime(pid int) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The command name is wrapped in parentheses and may itself contain spaces
+	stat := string(data)
+	end := strings.LastIndex(stat, ")")
+	if end == -1 {
+		return time.Time{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	// Fields after ")" start at field 3 (state); starttime is field 22
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return time.Time{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	bootTime, err := readBootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return bootTime.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+func readBootTime() (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no btime in /proc/stat")
+}
@@ -1,7 +1,46 @@
 package scanner
 
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Protocols a port can be checked for
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// PortSpec names a port together with its protocol, e.g. 53/udp
+type PortSpec struct {
+	Port     int
+	Protocol string // ProtocolTCP or ProtocolUDP
+}
+
+func (ps PortSpec) String() string {
+	return fmt.Sprintf("%d/%s", ps.Port, ps.Protocol)
+}
+
+// sortSpecs returns the specs in seen, TCP before UDP, then by port
+func sortSpecs(seen map[PortSpec]bool) []PortSpec {
+	specs := make([]PortSpec, 0, len(seen))
+	for spec := range seen {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].Protocol != specs[j].Protocol {
+			return specs[i].Protocol < specs[j].Protocol
+		}
+		return specs[i].Port < specs[j].Port
+	})
+	return specs
+}
+
 type PortStatus struct {
 	Port        int
+	Protocol    string // ProtocolTCP or ProtocolUDP
 	IsAvailable bool
 	ProcessName string
 	PID         int
@@ -10,12 +49,104 @@ type PortStatus struct {
 	CommandLine string // New: Full command
 	StartTime   string // New: Process start time
 	MemoryUsage string // New: Memory consumption
+
+	// Typed counterparts of the display strings above, zero when unknown
+	MemoryBytes int64
+	Started     time.Time
+	ErrorCode   string // One of the ErrCode constants when Error is set
+
+	// Why the port couldn't be bound: one of the Reason constants, and the
+	// error as the OS reported it. Empty when the port is available.
+	Reason    string
+	BindError string
+
+	// Local addresses the port is bound on ("0.0.0.0", "::1", ...) and the
+	// Family constant they add up to. Empty when nothing is bound.
+	Addresses []string
+	Family    string
+
+	// Every process involved in holding the port, the socket's owner first.
+	// PID and the fields above describe Owners[0].
+	Owners []Owner
+
+	// The service declaring the port in the project manifest, and whether
+	// that service can do without it. Empty when the port isn't declared.
+	Service  string
+	Optional bool
+
+	// Where --auto-detect found the port, e.g. "package.json:12"
+	DetectedIn []string
+
+	// The Docker container publishing the port, nil when it isn't one
+	Container *Container
+
+	Analysis *ProcessAnalysis // Owning process details, nil when not analyzed
+}
+
+// Spec returns the port and protocol this status is for
+func (ps *PortStatus) Spec() PortSpec {
+	return PortSpec{Port: ps.Port, Protocol: ps.Protocol}
 }
 
+// Error codes for PortStatus.ErrorCode. They are part of the JSON output,
+// so existing values must not change.
+const (
+	ErrCodeOwnerLookup = "owner_lookup_failed" // port is taken but the owner couldn't be resolved
+	ErrCodeScanFailed  = "scan_failed"         // the port could not be checked at all
+)
+
 type PortScanner interface {
+	// CheckPort checks a single TCP port
 	CheckPort(port int) (*PortStatus, error)
+	// CheckPorts checks many ports against one socket-table snapshot.
+	// Results are returned in the order of specs.
+	CheckPorts(ctx context.Context, specs []PortSpec) ([]*PortStatus, error)
+	// Listening lists every listening TCP port and every UDP port bound
+	// without a fixed peer, sorted by protocol and port
+	Listening(ctx context.Context) ([]PortSpec, error)
+	// Backend names where socket data comes from, one of the Backend constants
+	Backend() string
+	// BindAddress is Options.BindAddress, "" when every address is checked
+	BindAddress() string
+}
+
+type Options struct {
+	// BindAddress is the local address availability is tested on, e.g.
+	// 127.0.0.1 or ::1. Empty means every address, like a server on ":port".
+	BindAddress string
+	// Backend is a Backend constant. Empty or BackendAuto picks the best one
+	// that works here, see SelectBackend.
+	Backend string
+	// DockerSocket is the Docker Engine API socket used to name the
+	// containers behind published ports. Empty means DockerSocketPath.
+	DockerSocket string
 }
 
-func NewScanner() PortScanner {
-	return &MacScanner{}
+// NewScanner builds the scanner for opts.Backend. A named backend is used
+// as is; callers that want a fallback run SelectBackend first.
+func NewScanner(opts Options) PortScanner {
+	backend := opts.Backend
+	if backend == "" || backend == BackendAuto {
+		backend, _ = SelectBackend(BackendAuto)
+	}
+
+	dockerSocket := opts.DockerSocket
+	if dockerSocket == "" {
+		dockerSocket = DockerSocketPath()
+	}
+
+	switch backend {
+	case BackendLsof:
+		return &MacScanner{bindAddress: opts.BindAddress, dockerSocket: dockerSocket}
+	case BackendNetlink:
+		return &LinuxScanner{bindAddress: opts.BindAddress, dockerSocket: dockerSocket, backend: BackendNetlink, snapshot: procSnapshot(netlinkSocketTables)}
+	case BackendProc:
+		return &LinuxScanner{bindAddress: opts.BindAddress, dockerSocket: dockerSocket, backend: BackendProc, snapshot: procSnapshot(readSocketTables)}
+	case BackendSS:
+		return &LinuxScanner{bindAddress: opts.BindAddress, dockerSocket: dockerSocket, backend: BackendSS, snapshot: ssSnapshot}
+	}
+	// Bind checks still work; every owner lookup reports the missing backend
+	return &LinuxScanner{bindAddress: opts.BindAddress, dockerSocket: dockerSocket, backend: BackendNone, snapshot: func() (*socketSnapshot, error) {
+		return nil, errNoBackend
+	}}
 }
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ssStates maps ss state names to the kernel's TCP state numbers used by
+// /proc/net and netlink. UDP reports UNCONN (closed) or ESTAB.
+var ssStates = map[string]int{
+	"ESTAB":      tcpEstablished,
+	"SYN-SENT":   0x02,
+	"SYN-RECV":   0x03,
+	"FIN-WAIT-1": 0x04,
+	"FIN-WAIT-2": 0x05,
+	"TIME-WAIT":  tcpTimeWait,
+	"UNCONN":     udpUnconnected,
+	"CLOSE-WAIT": 0x08,
+	"LAST-ACK":   0x09,
+	"LISTEN":     tcpListen,
+	"CLOSING":    0x0B,
+}
+
+// ssUser matches one process in `users:(("nginx",pid=101,fd=6),("nginx",pid=100,fd=6))`
+var ssUser = regexp.MustCompile(`\("((?:[^"\\]|\\.)*)",pid=(\d+),fd=\d+\)`)
+
+func probeSS() error {
+	_, err := exec.LookPath("ss")
+	return err
+}
+
+// ssSnapshot lists every TCP and UDP socket, listening or not, with one ss
+// run. -e adds uid and inode, -p the owning processes.
+func ssSnapshot() (*socketSnapshot, error) {
+	output, err := exec.Command("ss", "-H", "-a", "-t", "-u", "-n", "-p", "-e").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ss: %w", err)
+	}
+	return parseSSOutput(string(output)), nil
+}
+
+func parseSSOutput(output string) *socketSnapshot {
+	snapshot := &socketSnapshot{
+		inodeOwners:  make(map[uint64][]int),
+		processNames: make(map[int]string),
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		entry, ok := parseSSLine(line)
+		if !ok {
+			continue
+		}
+		snapshot.entries = append(snapshot.entries, entry)
+
+		for _, match := range ssUser.FindAllStringSubmatch(line, -1) {
+			pid, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+			snapshot.processNames[pid] = match[1]
+			if entry.Inode != 0 && !containsPID(snapshot.inodeOwners[entry.Inode], pid) {
+				snapshot.inodeOwners[entry.Inode] = append(snapshot.inodeOwners[entry.Inode], pid)
+			}
+		}
+	}
+	return snapshot
+}
+
+// parseSSLine parses one line of `ss -Hatunpe`:
+// "tcp LISTEN 0 511 [::]:80 [::]:* users:(("nginx",pid=101,fd=6)) uid:33 ino:4242 sk:1 <->"
+func parseSSLine(line string) (socketEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return socketEntry{}, false
+	}
+
+	protocol := fields[0]
+	if protocol != ProtocolTCP && protocol != ProtocolUDP {
+		return socketEntry{}, false
+	}
+	state, ok := ssStates[fields[1]]
+	if !ok {
+		return socketEntry{}, false
+	}
+	rxQueue, err1 := strconv.Atoi(fields[2])
+	txQueue, err2 := strconv.Atoi(fields[3])
+	localIP, localPort, err3 := parseSSAddr(fields[4])
+	remoteIP, remotePort, err4 := parseSSAddr(fields[5])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return socketEntry{}, false
+	}
+
+	entry := socketEntry{
+		Protocol:   protocol,
+		LocalIP:    localIP,
+		LocalPort:  localPort,
+		RemoteIP:   remoteIP,
+		RemotePort: remotePort,
+		State:      state,
+		TxQueue:    txQueue,
+		RxQueue:    rxQueue,
+	}
+	// ss only prints uid when it isn't 0
+	for _, field := range fields[6:] {
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "uid":
+			entry.UID, _ = strconv.Atoi(value)
+		case "ino":
+			entry.Inode, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	return entry, true
+}
+
+// parseSSAddr parses "127.0.0.1:3000", "[::1]:3000", "127.0.0.53%lo:53",
+// "[fe80::1]%eth0:546", "*:8080" and "0.0.0.0:*". A bare "*" host is a
+// dual-stack IPv6 wildcard; a "*" port is 0.
+func parseSSAddr(s string) (net.IP, int, error) {
+	idx := strings.LastIndex(s, ":")
+	if idx == -1 {
+		return nil, 0, fmt.Errorf("invalid ss address: %s", s)
+	}
+	host, portStr := s[:idx], s[idx+1:]
+
+	port := 0
+	if portStr != "*" {
+		var err error
+		if port, err = strconv.Atoi(portStr); err != nil {
+			return nil, 0, fmt.Errorf("invalid ss address: %s", s)
+		}
+	}
+
+	host, _, _ = strings.Cut(host, "%")
+	host = strings.Trim(host, "[]")
+	if host == "*" {
+		return net.IPv6unspecified, port, nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, fmt.Errorf("invalid ss address: %s", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil && !strings.Contains(host, ":") {
+		ip = ip4 // same 4-byte form as /proc/net/tcp
+	}
+	return ip, port, nil
+}
+
+func containsPID(pids []int, pid int) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
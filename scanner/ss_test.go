@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSSAddr(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantIP   net.IP
+		wantPort int
+		wantErr  bool
+	}{
+		{"127.0.0.1:3000", net.IPv4(127, 0, 0, 1).To4(), 3000, false},
+		{"0.0.0.0:*", net.IPv4zero.To4(), 0, false},
+		{"[::1]:3000", net.IPv6loopback, 3000, false},
+		{"[::]:80", net.IPv6unspecified, 80, false},
+		{"*:8080", net.IPv6unspecified, 8080, false},
+		{"*:*", net.IPv6unspecified, 0, false},
+		{"127.0.0.53%lo:53", net.IPv4(127, 0, 0, 53).To4(), 53, false},
+		{"[fe80::1]%eth0:546", net.ParseIP("fe80::1"), 546, false},
+		{"[::ffff:127.0.0.1]:9000", net.ParseIP("::ffff:127.0.0.1"), 9000, false},
+		{"localhost:80", nil, 0, true},
+		{"127.0.0.1:http", nil, 0, true},
+		{"garbage", nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ip, port, err := parseSSAddr(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSSAddr(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// Compare bytes: IPv4 addresses must keep /proc/net's 4-byte form
+			if !reflect.DeepEqual(ip, tt.wantIP) || port != tt.wantPort {
+				t.Errorf("parseSSAddr(%q) = %v, %d, want %v, %d", tt.input, []byte(ip), port, []byte(tt.wantIP), tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestParseSSLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   socketEntry
+		wantOK bool
+	}{
+		{
+			name: "listener without uid",
+			line: `tcp   LISTEN    0      511     [::]:80     [::]:*     users:(("nginx",pid=1304,fd=7)) ino:31003 sk:6 v6only:1 <->`,
+			want: socketEntry{
+				Protocol: ProtocolTCP, LocalIP: net.IPv6unspecified, LocalPort: 80,
+				RemoteIP: net.IPv6unspecified, State: tcpListen, TxQueue: 511, Inode: 31003,
+			},
+			wantOK: true,
+		},
+		{
+			name: "connected udp socket",
+			line: `udp   ESTAB     0      0     10.0.0.5:41234     10.0.0.1:53     uid:1000 ino:777 sk:2 <->`,
+			want: socketEntry{
+				Protocol: ProtocolUDP, LocalIP: net.IPv4(10, 0, 0, 5).To4(), LocalPort: 41234,
+				RemoteIP: net.IPv4(10, 0, 0, 1).To4(), RemotePort: 53, State: tcpEstablished, UID: 1000, Inode: 777,
+			},
+			wantOK: true,
+		},
+		{name: "header", line: "Netid State  Recv-Q Send-Q Local Address:Port Peer Address:Port Process", wantOK: false},
+		{name: "unknown state", line: "tcp   BOGUS  0  0  127.0.0.1:80  0.0.0.0:*", wantOK: false},
+		{name: "other protocol", line: "raw   UNCONN 0  0  0.0.0.0:1  0.0.0.0:*", wantOK: false},
+		{name: "too short", line: "tcp LISTEN 0 511", wantOK: false},
+		{name: "empty", line: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSSLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseSSLine() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSSLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseSSOutput runs the parser over captures of `ss -H -a -t -u -n -p -e`,
+// as root and as an unprivileged user who only sees their own processes
+func TestParseSSOutput(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		entries      int
+		inodeOwners  map[uint64][]int
+		processNames map[int]string
+	}{
+		{
+			fixture: "ss_root.txt",
+			entries: 12,
+			inodeOwners: map[uint64][]int{
+				21544: {612},
+				20877: {590},
+				40110: {2210},
+				21545: {612},
+				31002: {1304, 1303, 1302}, // nginx master and workers share the socket
+				31003: {1304, 1303, 1302},
+				24580: {880},
+				51230: {4120},
+				32871: {1411, 1410},
+				51302: {4120},
+				51303: {4188},
+			},
+			processNames: map[int]string{
+				612: "systemd-resolve", 590: "systemd-network", 2210: "statsd",
+				1302: "nginx", 1303: "nginx", 1304: "nginx", 880: "postgres",
+				4120: "node", 1410: "php-fpm8.2", 1411: "php-fpm8.2", 4188: "postgres",
+			},
+		},
+		{
+			fixture: "ss_user.txt",
+			entries: 10,
+			inodeOwners: map[uint64][]int{
+				40110: {2210},
+				51230: {4120},
+				51302: {4120},
+			},
+			processNames: map[int]string{2210: "statsd", 4120: "node"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapshot := parseSSOutput(string(data))
+
+			if len(snapshot.entries) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(snapshot.entries), tt.entries)
+			}
+			if !reflect.DeepEqual(snapshot.inodeOwners, tt.inodeOwners) {
+				t.Errorf("inodeOwners = %v, want %v", snapshot.inodeOwners, tt.inodeOwners)
+			}
+			if !reflect.DeepEqual(snapshot.processNames, tt.processNames) {
+				t.Errorf("processNames = %v, want %v", snapshot.processNames, tt.processNames)
+			}
+		})
+	}
+}
+
+func TestParseSSOutputEntries(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ss_root.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := parseSSOutput(string(data))
+
+	find := func(protocol string, port int, state int) *socketEntry {
+		for i, entry := range snapshot.entries {
+			if entry.Protocol == protocol && entry.LocalPort == port && entry.State == state {
+				return &snapshot.entries[i]
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		protocol string
+		port     int
+		state    int
+		wantIP   string
+		wantUID  int
+	}{
+		{"scoped resolver stub", ProtocolUDP, 53, udpUnconnected, "127.0.0.53", 101},
+		{"scoped link-local dhcp", ProtocolUDP, 546, udpUnconnected, "fe80::5054:ff:fe12:3456", 100},
+		{"dual-stack udp wildcard", ProtocolUDP, 8125, udpUnconnected, "::", 1000},
+		{"dual-stack tcp wildcard", ProtocolTCP, 3000, tcpListen, "::", 1000},
+		{"root-owned listener", ProtocolTCP, 80, tcpListen, "0.0.0.0", 0},
+		{"time-wait without users", ProtocolTCP, 8080, tcpTimeWait, "127.0.0.1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := find(tt.protocol, tt.port, tt.state)
+			if entry == nil {
+				t.Fatalf("no %s entry for port %d", tt.protocol, tt.port)
+			}
+			if entry.LocalIP.String() != tt.wantIP || entry.UID != tt.wantUID {
+				t.Errorf("entry = %s uid %d, want %s uid %d", entry.LocalIP, entry.UID, tt.wantIP, tt.wantUID)
+			}
+		})
+	}
+}